@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"log/slog"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"quantum-teleport/internal/app"
 	transporthttp "quantum-teleport/internal/transport/http"
@@ -12,7 +17,16 @@ import (
 
 func main() {
 	log := logger.New()
-	application := app.New(log)
+	cfg, err := app.ConfigFromEnv()
+	if err != nil {
+		log.Error("invalid configuration", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+	application, err := app.NewWithConfig(log, cfg)
+	if err != nil {
+		log.Error("startup failed", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
 
 	mux := application.Routes()
 	handler := transporthttp.Middleware(mux, log)
@@ -20,9 +34,35 @@ func main() {
 	if p := os.Getenv("PORT"); p != "" {
 		port = p
 	}
+	server := &http.Server{Addr: ":" + port, Handler: handler}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	janitorDone := make(chan struct{})
+	go func() {
+		application.RunJanitor(ctx)
+		close(janitorDone)
+	}()
+
+	shutdownDone := make(chan struct{})
+	go func() {
+		defer close(shutdownDone)
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		application.Service.Shutdown()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Error("shutdown failed", slog.String("error", err.Error()))
+		}
+	}()
 
 	log.Info("starting server", slog.String("port", port))
-	if err := http.ListenAndServe(":"+port, handler); err != nil {
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
 		log.Error("server stopped", slog.String("error", err.Error()))
+		stop()
 	}
+	<-shutdownDone
+	<-janitorDone
+	log.Info("server stopped")
 }
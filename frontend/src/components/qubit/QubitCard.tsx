@@ -8,7 +8,9 @@ export type QubitView = {
   bloch: {
     theta: number;
     phi: number;
+    radius?: number;
   };
+  hidden?: boolean;
 };
 
 const roleLabels: Record<QubitView['role'], string> = {
@@ -48,7 +50,7 @@ function QubitCard({ qubit, emphasis, phaseActive, decoherence, hint, depth, sho
           <p className="qubit-id">Кубит {qubit.id}</p>
         </div>
       </div>
-      {showBloch ? (
+      {showBloch && !qubit.hidden ? (
         <BlochSphere
           label={phaseActive ? 'Фаза жива' : decoherence ? 'Коллапс' : 'Реальное состояние'}
           state={qubit.bloch}
@@ -3,6 +3,7 @@ import './bloch.css';
 type BlochVector = {
   theta: number;
   phi: number;
+  radius?: number;
 };
 
 interface Props {
@@ -16,8 +17,9 @@ const tau = Math.PI * 2;
 const toDegrees = (rad: number) => Math.round((rad * 180) / Math.PI);
 
 function project(vector: BlochVector, radius: number, center: number) {
-  const x = Math.sin(vector.theta) * Math.cos(vector.phi);
-  const y = Math.sin(vector.theta) * Math.sin(vector.phi);
+  const length = vector.radius ?? 1;
+  const x = length * Math.sin(vector.theta) * Math.cos(vector.phi);
+  const y = length * Math.sin(vector.theta) * Math.sin(vector.phi);
   const px = center + x * radius;
   const py = center - y * radius;
 
@@ -1,6 +1,7 @@
 package app
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 
@@ -15,20 +16,53 @@ type App struct {
 	HTTPRouter *transporthttp.Router
 	WSHandler  *transportws.Handler
 	Logger     *slog.Logger
+	config     Config
 }
 
-// New creates the application composition root.
+// New creates the application composition root with in-memory sessions.
 func New(logger *slog.Logger) *App {
-	svc := service.NewTeleportationService()
+	application, _ := NewWithConfig(logger, DefaultConfig())
+	return application
+}
+
+// NewWithConfig creates the application composition root using cfg to pick the session store.
+func NewWithConfig(logger *slog.Logger, cfg Config) (*App, error) {
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	options := []service.Option{
+		service.WithRoleTTL(cfg.RoleTTL),
+		service.WithIdleTimeout(cfg.SessionIdleTimeout),
+	}
+	if cfg.SessionStore == "file" {
+		store, err := service.NewFileStore(cfg.SessionDir)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, service.WithStore(store))
+		logger.Info("file session store", slog.String("dir", cfg.SessionDir), slog.Int("sessions", len(store.List())))
+	}
+
+	svc := service.NewTeleportationService(options...)
 	router := transporthttp.NewRouter(svc, logger)
 	wsHandler := transportws.NewHandler(svc, logger)
+	router.HandleReplay(wsHandler.ServeReplay)
 
 	return &App{
 		Service:    svc,
 		HTTPRouter: router,
 		WSHandler:  wsHandler,
 		Logger:     logger,
-	}
+		config:     cfg,
+	}, nil
+}
+
+// RunJanitor reaps expired roles and sessions until ctx is cancelled.
+func (a *App) RunJanitor(ctx context.Context) {
+	a.Service.RunJanitor(ctx, a.config.JanitorInterval, func(err error) {
+		a.Logger.Error("janitor failed", slog.String("error", err.Error()))
+	})
 }
 
 // Routes builds and returns the HTTP mux configured with handlers.
@@ -0,0 +1,75 @@
+package app
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"time"
+)
+
+// Config holds environment-driven settings of the backend.
+type Config struct {
+	// SessionStore selects where sessions live: "memory" (default) or "file".
+	SessionStore string
+	// SessionDir is the directory used by the file store.
+	SessionDir string
+	// SessionIdleTimeout is how long a session may stay untouched before it is deleted.
+	SessionIdleTimeout time.Duration
+	// RoleTTL is how long a disconnected role stays reserved for its token.
+	RoleTTL time.Duration
+	// JanitorInterval is how often expired roles and sessions are reaped.
+	JanitorInterval time.Duration
+}
+
+// DefaultConfig returns the settings used when no environment overrides are given.
+func DefaultConfig() Config {
+	return Config{
+		SessionStore:       "memory",
+		SessionDir:         "data/sessions",
+		SessionIdleTimeout: 2 * time.Hour,
+		RoleTTL:            60 * time.Second,
+		JanitorInterval:    30 * time.Second,
+	}
+}
+
+// ConfigFromEnv reads SESSION_STORE, SESSION_DIR, SESSION_IDLE_TIMEOUT, ROLE_TTL
+// and JANITOR_INTERVAL on top of DefaultConfig. Durations use Go syntax, e.g. "90m".
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+	if v := os.Getenv("SESSION_STORE"); v != "" {
+		cfg.SessionStore = strings.ToLower(v)
+	}
+	if v := os.Getenv("SESSION_DIR"); v != "" {
+		cfg.SessionDir = v
+	}
+
+	durations := map[string]*time.Duration{
+		"SESSION_IDLE_TIMEOUT": &cfg.SessionIdleTimeout,
+		"ROLE_TTL":             &cfg.RoleTTL,
+		"JANITOR_INTERVAL":     &cfg.JanitorInterval,
+	}
+	for name, target := range durations {
+		v := os.Getenv(name)
+		if v == "" {
+			continue
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return cfg, errors.New("invalid " + name + ": " + v)
+		}
+		*target = d
+	}
+	return cfg, cfg.validate()
+}
+
+func (c Config) validate() error {
+	switch c.SessionStore {
+	case "memory", "file":
+	default:
+		return errors.New("unknown session store: " + c.SessionStore)
+	}
+	if c.JanitorInterval <= 0 {
+		return errors.New("janitor interval must be positive")
+	}
+	return nil
+}
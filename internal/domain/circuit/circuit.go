@@ -0,0 +1,165 @@
+package circuit
+
+import (
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// Gate names an operation of the circuit model.
+type Gate string
+
+const (
+	// GatePrepare rotates |0> onto the unknown state, U(theta, phi, -phi).
+	GatePrepare Gate = "prepare"
+	GateH       Gate = "h"
+	GateX       Gate = "x"
+	GateZ       Gate = "z"
+	GateCNOT    Gate = "cx"
+	GateMeasure Gate = "measure"
+)
+
+// Op is one operation on the register. Qubits lists the operands, control
+// first for CNOT. Bit is the classical bit a measurement writes to, or the bit
+// that conditions the gate when Conditional is set.
+type Op struct {
+	Step        teleportation.Step `json:"step"`
+	Gate        Gate               `json:"gate"`
+	Qubits      []int              `json:"qubits"`
+	Bit         int                `json:"bit,omitempty"`
+	Conditional bool               `json:"conditional,omitempty"`
+}
+
+// Circuit is the gate-level form of a protocol run. Qubit 0 holds the
+// unknown state, qubit 1 is Alice's half of the Bell pair and qubit 2 Bob's,
+// the same layout the simulation uses.
+type Circuit struct {
+	Qubits int                      `json:"qubits"`
+	Bits   int                      `json:"bits"`
+	Labels []string                 `json:"labels"`
+	Steps  []teleportation.StepInfo `json:"steps"`
+	// Current is the index into Steps the session is on.
+	Current int `json:"current"`
+	// State is the prepared unknown state; nil leaves theta and phi as inputs.
+	State *qubit.BlochState `json:"state,omitempty"`
+	// Correction is the Pauli correction Bob actually chose, if any.
+	Correction *teleportation.Correction `json:"correction,omitempty"`
+	Ops        []Op                      `json:"ops"`
+}
+
+// Teleportation returns the textbook teleportation circuit with each gate
+// tagged by the step that performs it: the Bell pair on entangle, Alice's
+// CNOT on combine, the basis change and both measurements on measure and the
+// classically conditioned X and Z on reconstruct.
+func Teleportation(steps []teleportation.StepInfo) Circuit {
+	return Circuit{
+		Qubits: 3,
+		Bits:   2,
+		Labels: []string{"Алиса", "Алиса", "Боб"},
+		Steps:  steps,
+		Ops: []Op{
+			{Step: teleportation.StepEntangle, Gate: GatePrepare, Qubits: []int{0}},
+			{Step: teleportation.StepEntangle, Gate: GateH, Qubits: []int{1}},
+			{Step: teleportation.StepEntangle, Gate: GateCNOT, Qubits: []int{1, 2}},
+			{Step: teleportation.StepCombine, Gate: GateCNOT, Qubits: []int{0, 1}},
+			{Step: teleportation.StepMeasure, Gate: GateH, Qubits: []int{0}},
+			{Step: teleportation.StepMeasure, Gate: GateMeasure, Qubits: []int{0}, Bit: 0},
+			{Step: teleportation.StepMeasure, Gate: GateMeasure, Qubits: []int{1}, Bit: 1},
+			{Step: teleportation.StepReconstruct, Gate: GateX, Qubits: []int{2}, Bit: 1, Conditional: true},
+			{Step: teleportation.StepReconstruct, Gate: GateZ, Qubits: []int{2}, Bit: 0, Conditional: true},
+		},
+	}
+}
+
+// FromSession builds the circuit of a session. revealState fills in the
+// prepared angles, which only the instructor may know.
+func FromSession(session *teleportation.SessionState, revealState bool) Circuit {
+	c := Teleportation(session.Steps)
+	c.Current = session.StepIndex
+	if revealState {
+		state := session.HiddenState
+		c.State = &state
+	}
+	if session.Correction != nil {
+		applied := session.Correction.Applied
+		c.Correction = &applied
+	}
+	return c
+}
+
+// StepOf returns the index of the step an op belongs to, or -1.
+func (c Circuit) StepOf(op Op) int {
+	for i, step := range c.Steps {
+		if step.Key == op.Step {
+			return i
+		}
+	}
+	return -1
+}
+
+// Layout places the ops on a grid for drawing. Ops of one step stay in a
+// contiguous run of columns and start after the previous step; within a step
+// an op takes the first column after the ones used by its wires and, for a
+// conditional op, after the measurement of its bit. A step without ops gets
+// one empty column so it can still be highlighted. It returns the column of
+// every op, the column span [first, last] of every step and the column count.
+func (c Circuit) Layout() (columns []int, spans [][2]int, width int) {
+	columns = make([]int, len(c.Ops))
+	spans = make([][2]int, len(c.Steps))
+	wireFree := make([]int, c.Qubits)
+	bitFree := make([]int, c.Bits)
+
+	for i, step := range c.Steps {
+		start := width
+		last := start - 1
+		for j, op := range c.Ops {
+			if op.Step != step.Key {
+				continue
+			}
+			low, high := op.Qubits[0], op.Qubits[0]
+			for _, q := range op.Qubits {
+				low, high = min(low, q), max(high, q)
+			}
+			col := start
+			for q := low; q <= high; q++ {
+				col = max(col, wireFree[q])
+			}
+			if op.Conditional {
+				col = max(col, bitFree[op.Bit])
+			}
+			columns[j] = col
+			for q := low; q <= high; q++ {
+				wireFree[q] = col + 1
+			}
+			if op.Gate == GateMeasure {
+				bitFree[op.Bit] = col + 1
+			}
+			last = max(last, col)
+		}
+		if last < start {
+			last = start
+		}
+		spans[i] = [2]int{start, last}
+		width = last + 1
+		for q := range wireFree {
+			wireFree[q] = max(wireFree[q], width)
+		}
+		for b := range bitFree {
+			bitFree[b] = max(bitFree[b], width)
+		}
+	}
+	return columns, spans, width
+}
+
+// measuredAt returns, per wire, the column of its measurement or -1.
+func (c Circuit) measuredAt(columns []int) []int {
+	measured := make([]int, c.Qubits)
+	for q := range measured {
+		measured[q] = -1
+	}
+	for j, op := range c.Ops {
+		if op.Gate == GateMeasure {
+			measured[op.Qubits[0]] = columns[j]
+		}
+	}
+	return measured
+}
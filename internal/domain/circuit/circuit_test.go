@@ -0,0 +1,152 @@
+package circuit
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"strings"
+	"testing"
+
+	"quantum-teleport/internal/domain/quantum"
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// run executes the circuit on a state vector with the measurement outcomes
+// forced to bits, so every branch of the protocol can be checked.
+func run(c Circuit, state qubit.BlochState, bits [2]int) *quantum.StateVector {
+	reg := quantum.NewStateVector(c.Qubits)
+	gates := map[Gate]quantum.Gate{GateH: quantum.H, GateX: quantum.X, GateZ: quantum.Z}
+	for _, op := range c.Ops {
+		switch op.Gate {
+		case GatePrepare:
+			reg.Apply(quantum.Prepare(state), op.Qubits[0])
+		case GateCNOT:
+			reg.CNOT(op.Qubits[0], op.Qubits[1])
+		case GateMeasure:
+			reg.Collapse(op.Qubits[0], bits[op.Bit])
+		default:
+			if op.Conditional && bits[op.Bit] == 0 {
+				continue
+			}
+			reg.Apply(gates[op.Gate], op.Qubits[0])
+		}
+	}
+	return reg
+}
+
+func TestTeleportationCircuitRestoresStateOnEveryOutcome(t *testing.T) {
+	state, _ := qubit.NewBlochState(1.1, 2.3)
+	c := Teleportation(nil)
+	for _, bits := range [][2]int{{0, 0}, {0, 1}, {1, 0}, {1, 1}} {
+		reg := run(c, state, bits)
+		if f := quantum.Fidelity(reg.Reduced(2), state); math.Abs(f-1) > 1e-9 {
+			t.Fatalf("expected bob to hold the state for outcome %v, fidelity %f", bits, f)
+		}
+	}
+}
+
+func TestQASMRendersGatesAndHidesUnknownState(t *testing.T) {
+	session := &teleportation.SessionState{
+		Steps:       []teleportation.StepInfo{{Key: teleportation.StepEntangle}, {Key: teleportation.StepCombine}},
+		HiddenState: qubit.BlochState{Theta: 0.5, Phi: 1.5, Radius: 1},
+	}
+
+	public := FromSession(session, false).QASM()
+	for _, line := range []string{
+		"OPENQASM 3.0;",
+		"input angle theta;",
+		"U(theta, phi, -phi) q[0];",
+		"cx q[1], q[2];",
+		"c[1] = measure q[1];",
+		"if (c[1]) x q[2];",
+		"if (c[0]) z q[2];",
+	} {
+		if !strings.Contains(public, line) {
+			t.Fatalf("expected %q in\n%s", line, public)
+		}
+	}
+	if strings.Contains(public, "0.5") {
+		t.Fatalf("expected the prepared angles to stay hidden, got\n%s", public)
+	}
+
+	revealed := FromSession(session, true).QASM()
+	if !strings.Contains(revealed, "U(0.5, 1.5, -1.5) q[0];") || strings.Contains(revealed, "input angle") {
+		t.Fatalf("expected literal angles for the instructor, got\n%s", revealed)
+	}
+}
+
+func testSteps() []teleportation.StepInfo {
+	return []teleportation.StepInfo{
+		{Key: teleportation.StepEntangle, Title: "Подготовка"},
+		{Key: teleportation.StepCombine, Title: "Объединение"},
+		{Key: teleportation.StepMeasure, Title: "Измерение"},
+		{Key: teleportation.StepSend, Title: "Передача"},
+		{Key: teleportation.StepReconstruct, Title: "Восстановление"},
+		{Key: teleportation.StepComplete, Title: "Готово"},
+	}
+}
+
+func TestLayoutKeepsStepsInOrder(t *testing.T) {
+	c := Teleportation(testSteps())
+	columns, spans, width := c.Layout()
+	for j, op := range c.Ops {
+		span := spans[c.StepOf(op)]
+		if columns[j] < span[0] || columns[j] > span[1] {
+			t.Fatalf("expected %s on %s within columns %v, got %d", op.Gate, op.Step, span, columns[j])
+		}
+	}
+	for i := 1; i < len(spans); i++ {
+		if spans[i][0] != spans[i-1][1]+1 {
+			t.Fatalf("expected contiguous step columns, got %v", spans)
+		}
+	}
+	if spans[3][0] != spans[3][1] || width != spans[len(spans)-1][1]+1 {
+		t.Fatalf("expected the send step to get one empty column, got %v and width %d", spans, width)
+	}
+	if columns[7] >= columns[8] {
+		t.Fatalf("expected X before Z on bob's wire, got %v", columns)
+	}
+}
+
+func TestQuantikzHighlightsCurrentStep(t *testing.T) {
+	c := Teleportation(testSteps())
+	c.Current = 2
+	tex := c.Quantikz()
+	for _, fragment := range []string{
+		`\begin{quantikz}`,
+		`\gate{U(\theta, \phi, -\phi)}`,
+		`\ctrl{1}`,
+		`\targ{}`,
+		`\meter{}`,
+		`\gate{X^{c_{1}}}`,
+		`\gate{Z^{c_{0}}}`,
+		`background]{Измерение}`,
+		`\end{quantikz}`,
+	} {
+		if !strings.Contains(tex, fragment) {
+			t.Fatalf("expected %q in\n%s", fragment, tex)
+		}
+	}
+	if rows := strings.Count(tex, `\lstick`); rows != 3 {
+		t.Fatalf("expected three wires, got %d", rows)
+	}
+}
+
+func TestSVGIsWellFormedAndHighlightsCurrentStep(t *testing.T) {
+	c := Teleportation(testSteps())
+	c.Current = 4
+	svg := c.SVG()
+	decoder := xml.NewDecoder(strings.NewReader(svg))
+	for {
+		if _, err := decoder.Token(); err != nil {
+			if err != io.EOF {
+				t.Fatalf("expected well-formed SVG, got %v\n%s", err, svg)
+			}
+			break
+		}
+	}
+	if strings.Count(svg, `class="current-step"`) != 1 || !strings.Contains(svg, ">Восстановление</text>") {
+		t.Fatalf("expected the reconstruct step to be highlighted, got\n%s", svg)
+	}
+}
@@ -0,0 +1,67 @@
+package circuit
+
+import (
+	"strconv"
+	"strings"
+)
+
+// QASM renders the circuit as an OpenQASM 3 program using stdgates.inc.
+// Without a known state the preparation angles become program inputs.
+func (c Circuit) QASM() string {
+	var b strings.Builder
+	b.WriteString("OPENQASM 3.0;\n")
+	b.WriteString("include \"stdgates.inc\";\n\n")
+	b.WriteString("// q[0]: unknown state (Alice), q[1]: Alice's half of the Bell pair, q[2]: Bob's half\n")
+	theta, phi, lambda := "theta", "phi", "-phi"
+	if c.State != nil {
+		theta, phi, lambda = formatAngle(c.State.Theta), formatAngle(c.State.Phi), formatAngle(-c.State.Phi)
+	} else {
+		b.WriteString("input angle theta;\n")
+		b.WriteString("input angle phi;\n")
+	}
+	b.WriteString("qubit[" + strconv.Itoa(c.Qubits) + "] q;\n")
+	b.WriteString("bit[" + strconv.Itoa(c.Bits) + "] c;\n")
+
+	step := ""
+	for _, op := range c.Ops {
+		if string(op.Step) != step {
+			step = string(op.Step)
+			b.WriteString("\n// " + step + "\n")
+		}
+		switch op.Gate {
+		case GatePrepare:
+			b.WriteString("U(" + theta + ", " + phi + ", " + lambda + ") " + qref(op.Qubits[0]) + ";\n")
+		case GateMeasure:
+			b.WriteString(bref(op.Bit) + " = measure " + qref(op.Qubits[0]) + ";\n")
+		default:
+			operands := make([]string, len(op.Qubits))
+			for i, q := range op.Qubits {
+				operands[i] = qref(q)
+			}
+			line := string(op.Gate) + " " + strings.Join(operands, ", ") + ";"
+			if op.Conditional {
+				line = "if (" + bref(op.Bit) + ") " + line
+			}
+			b.WriteString(line + "\n")
+		}
+	}
+	if c.Correction != nil {
+		b.WriteString("// Bob chose the correction " + string(*c.Correction) + "\n")
+	}
+	return b.String()
+}
+
+func qref(q int) string {
+	return "q[" + strconv.Itoa(q) + "]"
+}
+
+func bref(bit int) string {
+	return "c[" + strconv.Itoa(bit) + "]"
+}
+
+func formatAngle(a float64) string {
+	if a == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(a, 'f', -1, 64)
+}
@@ -0,0 +1,121 @@
+package circuit
+
+import (
+	"html"
+	"strconv"
+	"strings"
+)
+
+// Drawing metrics of the SVG renderer, in pixels.
+const (
+	svgColumn = 72
+	svgRow    = 56
+	svgLeft   = 120
+	svgTop    = 48
+	svgGate   = 36
+)
+
+var svgWireColors = []string{"#7c3aed", "#7c3aed", "#10b981"}
+
+// SVG renders the circuit as a standalone SVG document with the columns of
+// the current step shaded and titled.
+func (c Circuit) SVG() string {
+	columns, spans, width := c.Layout()
+	measured := c.measuredAt(columns)
+	right := svgLeft + width*svgColumn + 24
+	height := svgTop + c.Qubits*svgRow + 16
+
+	var b strings.Builder
+	b.WriteString(`<svg xmlns="http://www.w3.org/2000/svg" width="` + itoa(right) + `" height="` + itoa(height) +
+		`" viewBox="0 0 ` + itoa(right) + ` ` + itoa(height) + `" font-family="sans-serif" font-size="14">` + "\n")
+	b.WriteString(`<title>Квантовая телепортация</title>` + "\n")
+	b.WriteString(`<rect width="100%" height="100%" fill="#ffffff"/>` + "\n")
+
+	if c.Current >= 0 && c.Current < len(spans) {
+		span := spans[c.Current]
+		x := svgLeft + span[0]*svgColumn + 2
+		w := (span[1]-span[0]+1)*svgColumn - 4
+		b.WriteString(`<rect class="current-step" x="` + itoa(x) + `" y="` + itoa(svgTop-12) + `" width="` + itoa(w) +
+			`" height="` + itoa(c.Qubits*svgRow+12) + `" rx="8" fill="#ede9fe" stroke="#7c3aed" stroke-dasharray="6 4"/>` + "\n")
+		b.WriteString(`<text x="` + itoa(x+w/2) + `" y="` + itoa(svgTop-20) + `" text-anchor="middle" fill="#5b21b6">` +
+			html.EscapeString(c.Steps[c.Current].Title) + `</text>` + "\n")
+	}
+
+	for q := 0; q < c.Qubits; q++ {
+		y := rowY(q)
+		b.WriteString(`<text x="12" y="` + itoa(y+5) + `" fill="` + svgWireColors[q%len(svgWireColors)] + `">` +
+			html.EscapeString(c.Labels[q]) + ` |0⟩</text>` + "\n")
+		end := right - 12
+		if measured[q] >= 0 {
+			mx := colX(measured[q])
+			b.WriteString(line(svgLeft, y, mx, y))
+			b.WriteString(line(mx, y-2, end, y-2))
+			b.WriteString(line(mx, y+2, end, y+2))
+		} else {
+			b.WriteString(line(svgLeft, y, end, y))
+		}
+	}
+
+	for j, op := range c.Ops {
+		x := colX(columns[j])
+		switch op.Gate {
+		case GateCNOT:
+			cy, ty := rowY(op.Qubits[0]), rowY(op.Qubits[1])
+			b.WriteString(line(x, cy, x, ty))
+			b.WriteString(`<circle cx="` + itoa(x) + `" cy="` + itoa(cy) + `" r="5" fill="#1f2937"/>` + "\n")
+			b.WriteString(`<circle cx="` + itoa(x) + `" cy="` + itoa(ty) + `" r="11" fill="#ffffff" stroke="#1f2937" stroke-width="1.5"/>` + "\n")
+			b.WriteString(line(x-11, ty, x+11, ty))
+			b.WriteString(line(x, ty-11, x, ty+11))
+		case GateMeasure:
+			y := rowY(op.Qubits[0])
+			b.WriteString(box(x, y, svgGate))
+			b.WriteString(`<path d="M ` + itoa(x-11) + ` ` + itoa(y+7) + ` A 11 11 0 0 1 ` + itoa(x+11) + ` ` + itoa(y+7) +
+				`" fill="none" stroke="#1f2937" stroke-width="1.5"/>` + "\n")
+			b.WriteString(line(x, y+7, x+8, y-9))
+		case GatePrepare:
+			y := rowY(op.Qubits[0])
+			b.WriteString(box(x, y, svgColumn-6))
+			b.WriteString(`<text x="` + itoa(x) + `" y="` + itoa(y+4) + `" text-anchor="middle" font-size="10">` +
+				html.EscapeString(c.svgPrepare()) + `</text>` + "\n")
+		default:
+			y := rowY(op.Qubits[0])
+			b.WriteString(box(x, y, svgGate))
+			label := `<tspan>` + strings.ToUpper(string(op.Gate)) + `</tspan>`
+			if op.Conditional {
+				label += `<tspan dy="-7" font-size="10">c` + itoa(op.Bit) + `</tspan>`
+			}
+			b.WriteString(`<text x="` + itoa(x) + `" y="` + itoa(y+5) + `" text-anchor="middle">` + label + `</text>` + "\n")
+		}
+	}
+	b.WriteString("</svg>\n")
+	return b.String()
+}
+
+func (c Circuit) svgPrepare() string {
+	if c.State == nil {
+		return "U(θ, φ, −φ)"
+	}
+	return "U(" + shortAngle(c.State.Theta) + ", " + shortAngle(c.State.Phi) + ")"
+}
+
+func colX(col int) int {
+	return svgLeft + col*svgColumn + svgColumn/2
+}
+
+func rowY(q int) int {
+	return svgTop + q*svgRow + svgRow/2
+}
+
+func line(x1, y1, x2, y2 int) string {
+	return `<line x1="` + itoa(x1) + `" y1="` + itoa(y1) + `" x2="` + itoa(x2) + `" y2="` + itoa(y2) +
+		`" stroke="#1f2937" stroke-width="1.5"/>` + "\n"
+}
+
+func box(x, y, w int) string {
+	return `<rect x="` + itoa(x-w/2) + `" y="` + itoa(y-svgGate/2) + `" width="` + itoa(w) + `" height="` + itoa(svgGate) +
+		`" rx="4" fill="#ffffff" stroke="#1f2937" stroke-width="1.5"/>` + "\n"
+}
+
+func itoa(v int) string {
+	return strconv.Itoa(v)
+}
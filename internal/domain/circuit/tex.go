@@ -0,0 +1,94 @@
+package circuit
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Quantikz renders the circuit as a quantikz environment for LaTeX, with the
+// columns of the current step in a shaded gate group titled by the step.
+// Conditional corrections are drawn as X^{c_1} and Z^{c_0}.
+func (c Circuit) Quantikz() string {
+	columns, spans, width := c.Layout()
+	measured := c.measuredAt(columns)
+
+	cells := make([][]string, c.Qubits)
+	for q := range cells {
+		cells[q] = make([]string, width)
+		for col := range cells[q] {
+			cells[q][col] = `\qw`
+			if measured[q] >= 0 && col > measured[q] {
+				cells[q][col] = `\cw`
+			}
+		}
+	}
+	for j, op := range c.Ops {
+		col := columns[j]
+		switch op.Gate {
+		case GatePrepare:
+			cells[op.Qubits[0]][col] = `\gate{` + c.texPrepare() + `}`
+		case GateCNOT:
+			control, target := op.Qubits[0], op.Qubits[1]
+			cells[control][col] = `\ctrl{` + strconv.Itoa(target-control) + `}`
+			cells[target][col] = `\targ{}`
+		case GateMeasure:
+			cells[op.Qubits[0]][col] = `\meter{}`
+		default:
+			label := strings.ToUpper(string(op.Gate))
+			if op.Conditional {
+				label += `^{c_{` + strconv.Itoa(op.Bit) + `}}`
+			}
+			cells[op.Qubits[0]][col] = `\gate{` + label + `}`
+		}
+	}
+	if c.Current >= 0 && c.Current < len(spans) {
+		span := spans[c.Current]
+		cells[0][span[0]] += `\gategroup[` + strconv.Itoa(c.Qubits) + `,steps=` + strconv.Itoa(span[1]-span[0]+1) +
+			`,style={dashed,rounded corners,fill=blue!10,inner xsep=2pt},background]{` + texEscape(c.Steps[c.Current].Title) + `}`
+	}
+
+	var b strings.Builder
+	if c.Current >= 0 && c.Current < len(c.Steps) {
+		b.WriteString("% Текущий шаг: " + c.Steps[c.Current].Title + "\n")
+	}
+	b.WriteString("\\begin{quantikz}\n")
+	for q := 0; q < c.Qubits; q++ {
+		end := `\qw`
+		if measured[q] >= 0 {
+			end = `\cw`
+		}
+		row := append([]string{`\lstick{` + texEscape(c.Labels[q]) + `: $\ket{0}$}`}, cells[q]...)
+		row = append(row, end)
+		b.WriteString(strings.Join(row, " & "))
+		if q < c.Qubits-1 {
+			b.WriteString(` \\`)
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\\end{quantikz}\n")
+	return b.String()
+}
+
+func (c Circuit) texPrepare() string {
+	if c.State == nil {
+		return `U(\theta, \phi, -\phi)`
+	}
+	return `U(` + shortAngle(c.State.Theta) + `, ` + shortAngle(c.State.Phi) + `, ` + shortAngle(-c.State.Phi) + `)`
+}
+
+// shortAngle formats an angle with two decimals for drawings.
+func shortAngle(a float64) string {
+	if a == 0 {
+		return "0"
+	}
+	return strconv.FormatFloat(a, 'f', 2, 64)
+}
+
+var texReplacer = strings.NewReplacer(
+	`\`, `\textbackslash{}`, `&`, `\&`, `%`, `\%`, `$`, `\$`, `#`, `\#`,
+	`_`, `\_`, `{`, `\{`, `}`, `\}`, `~`, `\textasciitilde{}`, `^`, `\textasciicircum{}`,
+)
+
+func texEscape(s string) string {
+	return texReplacer.Replace(s)
+}
@@ -0,0 +1,225 @@
+package quantum
+
+import (
+	"math"
+	"math/cmplx"
+
+	"quantum-teleport/internal/domain/qubit"
+)
+
+// DensityMatrix holds the 2^n x 2^n density operator of an n-qubit register.
+// Qubit 0 is the most significant bit of a basis index, as in StateVector.
+type DensityMatrix struct {
+	n   int
+	rho [][]complex128
+}
+
+// NewDensityMatrix creates a register of n qubits in the pure state |0...0><0...0|.
+func NewDensityMatrix(n int) *DensityMatrix {
+	d := &DensityMatrix{n: n, rho: zeroMatrix(1 << n)}
+	d.rho[0][0] = 1
+	return d
+}
+
+// DensityFromStateVector builds the projector |psi><psi| of a pure register.
+func DensityFromStateVector(s *StateVector) *DensityMatrix {
+	d := &DensityMatrix{n: s.n, rho: zeroMatrix(len(s.amp))}
+	for i, a := range s.amp {
+		for j, b := range s.amp {
+			d.rho[i][j] = a * cmplx.Conj(b)
+		}
+	}
+	return d
+}
+
+func zeroMatrix(dim int) [][]complex128 {
+	m := make([][]complex128, dim)
+	for i := range m {
+		m[i] = make([]complex128, dim)
+	}
+	return m
+}
+
+// Qubits returns the register size.
+func (d *DensityMatrix) Qubits() int {
+	return d.n
+}
+
+func (d *DensityMatrix) mask(q int) int {
+	return 1 << (d.n - 1 - q)
+}
+
+// Apply conjugates the register by a single-qubit gate on qubit q: rho -> G rho G†.
+func (d *DensityMatrix) Apply(g Gate, q int) {
+	d.rho = d.conjugated(g, q)
+}
+
+func (d *DensityMatrix) conjugated(g Gate, q int) [][]complex128 {
+	m := d.mask(q)
+	out := zeroMatrix(len(d.rho))
+	for r := range out {
+		copy(out[r], d.rho[r])
+	}
+	for c := range out {
+		for i := range out {
+			if i&m != 0 {
+				continue
+			}
+			a0, a1 := out[i][c], out[i|m][c]
+			out[i][c] = g[0][0]*a0 + g[0][1]*a1
+			out[i|m][c] = g[1][0]*a0 + g[1][1]*a1
+		}
+	}
+	for r := range out {
+		for j := range out {
+			if j&m != 0 {
+				continue
+			}
+			a0, a1 := out[r][j], out[r][j|m]
+			out[r][j] = a0*cmplx.Conj(g[0][0]) + a1*cmplx.Conj(g[0][1])
+			out[r][j|m] = a0*cmplx.Conj(g[1][0]) + a1*cmplx.Conj(g[1][1])
+		}
+	}
+	return out
+}
+
+// ApplyKraus applies the quantum channel given by Kraus operators to qubit q.
+func (d *DensityMatrix) ApplyKraus(ops []Gate, q int) {
+	sum := zeroMatrix(len(d.rho))
+	for _, k := range ops {
+		term := d.conjugated(k, q)
+		for i := range sum {
+			for j := range sum {
+				sum[i][j] += term[i][j]
+			}
+		}
+	}
+	d.rho = sum
+}
+
+// CNOT flips target whenever control is |1>.
+func (d *DensityMatrix) CNOT(control, target int) {
+	cm, tm := d.mask(control), d.mask(target)
+	perm := func(i int) int {
+		if i&cm != 0 {
+			return i ^ tm
+		}
+		return i
+	}
+	out := zeroMatrix(len(d.rho))
+	for i := range out {
+		for j := range out {
+			out[perm(i)][perm(j)] = d.rho[i][j]
+		}
+	}
+	d.rho = out
+}
+
+// Probability returns the Born-rule probability of observing qubit q as |1>.
+func (d *DensityMatrix) Probability(q int) float64 {
+	m := d.mask(q)
+	p := 0.0
+	for i := range d.rho {
+		if i&m != 0 {
+			p += real(d.rho[i][i])
+		}
+	}
+	return p
+}
+
+// JointProbabilities returns the Born-rule probabilities of the four outcomes of
+// measuring qubits a and b, indexed as 2*bitA + bitB.
+func (d *DensityMatrix) JointProbabilities(a, b int) [4]float64 {
+	am, bm := d.mask(a), d.mask(b)
+	var p [4]float64
+	for i := range d.rho {
+		k := 0
+		if i&am != 0 {
+			k += 2
+		}
+		if i&bm != 0 {
+			k++
+		}
+		p[k] += real(d.rho[i][i])
+	}
+	return p
+}
+
+// Measure projects qubit q in the computational basis using r in [0,1) as the
+// random draw and returns the observed bit.
+func (d *DensityMatrix) Measure(q int, r float64) int {
+	bit := 0
+	if r < d.Probability(q) {
+		bit = 1
+	}
+	d.Collapse(q, bit)
+	return bit
+}
+
+// MeasurePair samples a joint outcome of qubits a and b with r in [0,1) as the
+// random draw, collapses both and returns the bits with the outcome probability.
+func (d *DensityMatrix) MeasurePair(a, b int, r float64) (int, int, float64) {
+	return measurePair(d, a, b, r)
+}
+
+// Reset returns qubit q to |0> with the reset channel {|0><0|, |0><1|},
+// leaving the rest of the register as it was.
+func (d *DensityMatrix) Reset(q int) {
+	d.ApplyKraus([]Gate{{{1, 0}, {0, 0}}, {{0, 1}, {0, 0}}}, q)
+}
+
+// Collapse projects qubit q onto the given bit and renormalises the register.
+func (d *DensityMatrix) Collapse(q int, bit int) {
+	m := d.mask(q)
+	keep := func(i int) bool { return (i&m != 0) == (bit == 1) }
+	trace := 0.0
+	for i := range d.rho {
+		for j := range d.rho {
+			if !keep(i) || !keep(j) {
+				d.rho[i][j] = 0
+			}
+		}
+		trace += real(d.rho[i][i])
+	}
+	if trace == 0 {
+		return
+	}
+	scale := complex(1/trace, 0)
+	for i := range d.rho {
+		for j := range d.rho {
+			d.rho[i][j] *= scale
+		}
+	}
+}
+
+// Reduced returns the 2x2 reduced density matrix of qubit q.
+func (d *DensityMatrix) Reduced(q int) [2][2]complex128 {
+	m := d.mask(q)
+	var out [2][2]complex128
+	for i := range d.rho {
+		if i&m != 0 {
+			continue
+		}
+		out[0][0] += d.rho[i][i]
+		out[0][1] += d.rho[i][i|m]
+		out[1][0] += d.rho[i|m][i]
+		out[1][1] += d.rho[i|m][i|m]
+	}
+	return out
+}
+
+// Bloch derives the Bloch coordinates of qubit q from its reduced density matrix.
+func (d *DensityMatrix) Bloch(q int) qubit.BlochState {
+	return BlochFromDensity(d.Reduced(q))
+}
+
+// Purity returns Tr(rho^2) of the whole register.
+func (d *DensityMatrix) Purity() float64 {
+	p := 0.0
+	for i := range d.rho {
+		for j := range d.rho {
+			p += real(d.rho[i][j] * d.rho[j][i])
+		}
+	}
+	return math.Min(p, 1)
+}
@@ -0,0 +1,88 @@
+package quantum
+
+import (
+	"math"
+	"testing"
+
+	"quantum-teleport/internal/domain/qubit"
+)
+
+func TestDensityMatrixTracksStateVector(t *testing.T) {
+	pure := NewStateVector(3)
+	pure.Apply(Prepare(qubit.BlochState{Theta: 0.7, Phi: 1.9}), 0)
+	density := DensityFromStateVector(pure)
+
+	for _, reg := range []Register{pure, density} {
+		reg.Apply(H, 1)
+		reg.CNOT(1, 2)
+		reg.CNOT(0, 1)
+		reg.Apply(H, 0)
+	}
+
+	for q := 0; q < 3; q++ {
+		want, got := pure.Bloch(q), density.Bloch(q)
+		if math.Abs(want.Theta-got.Theta) > 1e-9 || math.Abs(want.Radius-got.Radius) > 1e-9 {
+			t.Fatalf("qubit %d: expected %+v, got %+v", q, want, got)
+		}
+	}
+	if p := density.Purity(); math.Abs(p-1) > 1e-9 {
+		t.Fatalf("expected unitary evolution to keep purity 1, got %f", p)
+	}
+}
+
+func TestNoiseChannelsShrinkBlochVector(t *testing.T) {
+	plus := qubit.BlochState{Theta: math.Pi / 2, Phi: 0}
+	cases := []struct {
+		channel Channel
+		radius  float64
+	}{
+		{ChannelDephasing, 0.6},
+		{ChannelDepolarizing, 1 - 4*0.2/3},
+		{ChannelAmplitudeDamping, math.Sqrt(0.8)},
+	}
+
+	for _, tc := range cases {
+		density := NewDensityMatrix(1)
+		density.Apply(Prepare(plus), 0)
+		ops, err := Kraus(tc.channel, 0.2)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", tc.channel, err)
+		}
+		density.ApplyKraus(ops, 0)
+
+		got := density.Bloch(0)
+		if tc.channel == ChannelAmplitudeDamping {
+			// Damping also pulls the vector towards |0>, so compare the equatorial part only.
+			got.Radius = got.Radius * math.Sin(got.Theta)
+		}
+		if math.Abs(got.Radius-tc.radius) > 1e-9 {
+			t.Fatalf("%s: expected radius %f, got %f", tc.channel, tc.radius, got.Radius)
+		}
+	}
+}
+
+func TestKrausRejectsInvalidProfiles(t *testing.T) {
+	if _, err := Kraus(ChannelDephasing, 1.5); err == nil {
+		t.Fatal("expected probability above 1 to be rejected")
+	}
+	if _, err := Kraus("bitflip", 0.1); err == nil {
+		t.Fatal("expected unknown channel to be rejected")
+	}
+}
+
+func TestResetReturnsMeasuredQubitToZero(t *testing.T) {
+	pure := NewStateVector(2)
+	pure.Apply(H, 1)
+	pure.Apply(X, 0)
+	mixed := DensityFromStateVector(pure)
+
+	for _, reg := range []Register{pure, mixed} {
+		reg.Reset(0)
+		if got := reg.Probability(0); math.Abs(got) > 1e-9 {
+			t.Fatalf("expected qubit 0 reset to |0>, got P(1)=%f", got)
+		}
+		if got := reg.Bloch(1); math.Abs(got.Theta-math.Pi/2) > 1e-9 || math.Abs(got.Radius-1) > 1e-9 {
+			t.Fatalf("expected qubit 1 to keep |+>, got %+v", got)
+		}
+	}
+}
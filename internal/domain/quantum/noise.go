@@ -0,0 +1,44 @@
+package quantum
+
+import (
+	"errors"
+	"math"
+)
+
+// Channel names a single-qubit noise model.
+type Channel string
+
+const (
+	// ChannelDepolarizing replaces the qubit by a random Pauli error with probability p.
+	ChannelDepolarizing Channel = "depolarizing"
+	// ChannelAmplitudeDamping relaxes |1> towards |0> with probability p.
+	ChannelAmplitudeDamping Channel = "amplitude_damping"
+	// ChannelDephasing applies a phase flip with probability p.
+	ChannelDephasing Channel = "dephasing"
+)
+
+// Kraus returns the Kraus operators of the channel for error probability p.
+func Kraus(channel Channel, p float64) ([]Gate, error) {
+	if p < 0 || p > 1 || math.IsNaN(p) {
+		return nil, errors.New("noise probability out of range")
+	}
+	keep := complex(math.Sqrt(1-p), 0)
+	switch channel {
+	case ChannelDepolarizing:
+		e := complex(math.Sqrt(p/3), 0)
+		return []Gate{scale(I, keep), scale(X, e), scale(Y, e), scale(Z, e)}, nil
+	case ChannelAmplitudeDamping:
+		return []Gate{
+			{{1, 0}, {0, keep}},
+			{{0, complex(math.Sqrt(p), 0)}, {0, 0}},
+		}, nil
+	case ChannelDephasing:
+		return []Gate{scale(I, keep), scale(Z, complex(math.Sqrt(p), 0))}, nil
+	default:
+		return nil, errors.New("unknown noise channel")
+	}
+}
+
+func scale(g Gate, k complex128) Gate {
+	return Gate{{g[0][0] * k, g[0][1] * k}, {g[1][0] * k, g[1][1] * k}}
+}
@@ -0,0 +1,57 @@
+package quantum
+
+import "quantum-teleport/internal/domain/qubit"
+
+// Register is a simulated multi-qubit system that protocols evolve step by step.
+// StateVector covers pure states, DensityMatrix adds mixed states and noise.
+type Register interface {
+	Qubits() int
+	Apply(g Gate, q int)
+	CNOT(control, target int)
+	Probability(q int) float64
+	JointProbabilities(a, b int) [4]float64
+	Measure(q int, r float64) int
+	MeasurePair(a, b int, r float64) (int, int, float64)
+	Collapse(q int, bit int)
+	Reset(q int)
+	Reduced(q int) [2][2]complex128
+	Bloch(q int) qubit.BlochState
+}
+
+var (
+	_ Register = (*StateVector)(nil)
+	_ Register = (*DensityMatrix)(nil)
+)
+
+// measurePair samples a joint outcome from the register's Born-rule probabilities.
+func measurePair(reg Register, a, b int, r float64) (int, int, float64) {
+	probs := reg.JointProbabilities(a, b)
+	k, acc := 3, 0.0
+	for i, p := range probs {
+		acc += p
+		if r < acc {
+			k = i
+			break
+		}
+	}
+	for k > 0 && probs[k] == 0 {
+		k--
+	}
+	bitA, bitB := k>>1, k&1
+	reg.Collapse(a, bitA)
+	reg.Collapse(b, bitB)
+	return bitA, bitB, probs[k]
+}
+
+// BellFidelity returns <Φ+|rho|Φ+>, the overlap of qubits a and b with the Bell
+// state (|00>+|11>)/√2. It probes a copy, so reg is left untouched.
+func BellFidelity(reg Register, a, b int) float64 {
+	probe, err := Capture(reg).Restore()
+	if err != nil {
+		return 0
+	}
+	// CNOT then H maps |Φ+> onto |00>.
+	probe.CNOT(a, b)
+	probe.Apply(H, a)
+	return probe.JointProbabilities(a, b)[0]
+}
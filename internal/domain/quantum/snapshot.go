@@ -0,0 +1,67 @@
+package quantum
+
+import "errors"
+
+// Snapshot is a serialisable copy of a register.
+type Snapshot struct {
+	Kind   string    `json:"kind"`
+	Qubits int       `json:"qubits"`
+	Real   []float64 `json:"real"`
+	Imag   []float64 `json:"imag"`
+}
+
+const (
+	kindStateVector   = "state_vector"
+	kindDensityMatrix = "density_matrix"
+)
+
+// Capture copies the register into a snapshot. Density matrices are stored row by row.
+func Capture(reg Register) Snapshot {
+	var values []complex128
+	snap := Snapshot{Qubits: reg.Qubits()}
+	switch r := reg.(type) {
+	case *StateVector:
+		snap.Kind = kindStateVector
+		values = r.amp
+	case *DensityMatrix:
+		snap.Kind = kindDensityMatrix
+		for _, row := range r.rho {
+			values = append(values, row...)
+		}
+	}
+	snap.Real = make([]float64, len(values))
+	snap.Imag = make([]float64, len(values))
+	for i, v := range values {
+		snap.Real[i], snap.Imag[i] = real(v), imag(v)
+	}
+	return snap
+}
+
+// Restore rebuilds an independent register from the snapshot.
+func (s Snapshot) Restore() (Register, error) {
+	dim := 1 << s.Qubits
+	size := dim
+	if s.Kind == kindDensityMatrix {
+		size = dim * dim
+	}
+	if s.Qubits <= 0 || len(s.Real) != size || len(s.Imag) != size {
+		return nil, errors.New("malformed register snapshot")
+	}
+
+	values := make([]complex128, size)
+	for i := range values {
+		values[i] = complex(s.Real[i], s.Imag[i])
+	}
+	switch s.Kind {
+	case kindStateVector:
+		return &StateVector{n: s.Qubits, amp: values}, nil
+	case kindDensityMatrix:
+		rho := make([][]complex128, dim)
+		for i := range rho {
+			rho[i] = values[i*dim : (i+1)*dim]
+		}
+		return &DensityMatrix{n: s.Qubits, rho: rho}, nil
+	default:
+		return nil, errors.New("unknown register kind")
+	}
+}
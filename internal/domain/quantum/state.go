@@ -0,0 +1,226 @@
+package quantum
+
+import (
+	"math"
+	"math/cmplx"
+
+	"quantum-teleport/internal/domain/qubit"
+)
+
+// Gate is a single-qubit unitary in the computational basis.
+type Gate [2][2]complex128
+
+var (
+	// I is the identity gate.
+	I = Gate{{1, 0}, {0, 1}}
+	// X is the Pauli bit-flip gate.
+	X = Gate{{0, 1}, {1, 0}}
+	// Y is the Pauli Y gate.
+	Y = Gate{{0, -1i}, {1i, 0}}
+	// Z is the Pauli phase-flip gate.
+	Z = Gate{{1, 0}, {0, -1}}
+	// H is the Hadamard gate.
+	H = Gate{{complex(1/math.Sqrt2, 0), complex(1/math.Sqrt2, 0)}, {complex(1/math.Sqrt2, 0), complex(-1/math.Sqrt2, 0)}}
+	// Sdg is the inverse phase gate S†; followed by H it maps the Y basis onto the computational one.
+	Sdg = Gate{{1, 0}, {0, -1i}}
+)
+
+// Prepare returns the rotation that maps |0> onto the pure state with the given Bloch angles.
+func Prepare(b qubit.BlochState) Gate {
+	c := complex(math.Cos(b.Theta/2), 0)
+	s := math.Sin(b.Theta / 2)
+	return Gate{
+		{c, -cmplx.Rect(s, -b.Phi)},
+		{cmplx.Rect(s, b.Phi), c},
+	}
+}
+
+// StateVector holds the complex amplitudes of an n-qubit register.
+// Qubit 0 is the most significant bit of a basis index.
+type StateVector struct {
+	n   int
+	amp []complex128
+}
+
+// NewStateVector creates a register of n qubits initialised to |0...0>.
+func NewStateVector(n int) *StateVector {
+	amp := make([]complex128, 1<<n)
+	amp[0] = 1
+	return &StateVector{n: n, amp: amp}
+}
+
+// Qubits returns the register size.
+func (s *StateVector) Qubits() int {
+	return s.n
+}
+
+// Amplitudes returns a copy of the amplitudes in basis order.
+func (s *StateVector) Amplitudes() []complex128 {
+	return append([]complex128(nil), s.amp...)
+}
+
+func (s *StateVector) mask(q int) int {
+	return 1 << (s.n - 1 - q)
+}
+
+// Apply applies a single-qubit gate to qubit q.
+func (s *StateVector) Apply(g Gate, q int) {
+	m := s.mask(q)
+	for i := range s.amp {
+		if i&m != 0 {
+			continue
+		}
+		a0, a1 := s.amp[i], s.amp[i|m]
+		s.amp[i] = g[0][0]*a0 + g[0][1]*a1
+		s.amp[i|m] = g[1][0]*a0 + g[1][1]*a1
+	}
+}
+
+// CNOT flips target whenever control is |1>.
+func (s *StateVector) CNOT(control, target int) {
+	cm, tm := s.mask(control), s.mask(target)
+	for i := range s.amp {
+		if i&cm != 0 && i&tm == 0 {
+			s.amp[i], s.amp[i|tm] = s.amp[i|tm], s.amp[i]
+		}
+	}
+}
+
+// Probability returns the Born-rule probability of observing qubit q as |1>.
+func (s *StateVector) Probability(q int) float64 {
+	m := s.mask(q)
+	p := 0.0
+	for i, a := range s.amp {
+		if i&m != 0 {
+			p += real(a)*real(a) + imag(a)*imag(a)
+		}
+	}
+	return p
+}
+
+// Measure projects qubit q in the computational basis using r in [0,1) as the
+// random draw and returns the observed bit.
+func (s *StateVector) Measure(q int, r float64) int {
+	bit := 0
+	if r < s.Probability(q) {
+		bit = 1
+	}
+	s.Collapse(q, bit)
+	return bit
+}
+
+// JointProbabilities returns the Born-rule probabilities of the four outcomes of
+// measuring qubits a and b, indexed as 2*bitA + bitB.
+func (s *StateVector) JointProbabilities(a, b int) [4]float64 {
+	am, bm := s.mask(a), s.mask(b)
+	var p [4]float64
+	for i, amp := range s.amp {
+		k := 0
+		if i&am != 0 {
+			k += 2
+		}
+		if i&bm != 0 {
+			k++
+		}
+		p[k] += real(amp)*real(amp) + imag(amp)*imag(amp)
+	}
+	return p
+}
+
+// MeasurePair samples a joint outcome of qubits a and b with r in [0,1) as the
+// random draw, collapses both and returns the bits with the outcome probability.
+func (s *StateVector) MeasurePair(a, b int, r float64) (int, int, float64) {
+	return measurePair(s, a, b, r)
+}
+
+// Collapse projects qubit q onto the given bit and renormalises the register.
+func (s *StateVector) Collapse(q int, bit int) {
+	m := s.mask(q)
+	norm := 0.0
+	for i, a := range s.amp {
+		if (i&m != 0) != (bit == 1) {
+			s.amp[i] = 0
+			continue
+		}
+		norm += real(a)*real(a) + imag(a)*imag(a)
+	}
+	if norm == 0 {
+		return
+	}
+	scale := complex(1/math.Sqrt(norm), 0)
+	for i := range s.amp {
+		s.amp[i] *= scale
+	}
+}
+
+// basisTolerance is how far from 0 or 1 the probability of a measured qubit
+// may drift through rounding.
+const basisTolerance = 1e-9
+
+// Reset returns a measured qubit to |0> so it can be reused. A state vector
+// cannot hold the mixture resetting an entangled or superposed qubit would
+// leave, and picking a branch would silently disturb the other qubits, so
+// Reset panics unless q is already in a basis state: measure it first.
+func (s *StateVector) Reset(q int) {
+	p := s.Probability(q)
+	switch {
+	case p < basisTolerance:
+	case p > 1-basisTolerance:
+		s.Apply(X, q)
+	default:
+		panic("quantum: reset of a qubit that is not in a basis state")
+	}
+	s.Collapse(q, 0)
+}
+
+// Reduced returns the 2x2 reduced density matrix of qubit q.
+func (s *StateVector) Reduced(q int) [2][2]complex128 {
+	m := s.mask(q)
+	var rho [2][2]complex128
+	for i, a := range s.amp {
+		if i&m != 0 {
+			continue
+		}
+		b := s.amp[i|m]
+		rho[0][0] += a * cmplx.Conj(a)
+		rho[0][1] += a * cmplx.Conj(b)
+		rho[1][0] += b * cmplx.Conj(a)
+		rho[1][1] += b * cmplx.Conj(b)
+	}
+	return rho
+}
+
+// Bloch derives the Bloch coordinates of qubit q from its reduced density matrix.
+func (s *StateVector) Bloch(q int) qubit.BlochState {
+	return BlochFromDensity(s.Reduced(q))
+}
+
+// Fidelity returns <psi|rho|psi> for the pure target state given by its Bloch angles.
+func Fidelity(rho [2][2]complex128, target qubit.BlochState) float64 {
+	u := Prepare(target)
+	psi := [2]complex128{u[0][0], u[1][0]}
+	f := complex(0, 0)
+	for i := 0; i < 2; i++ {
+		for j := 0; j < 2; j++ {
+			f += cmplx.Conj(psi[i]) * rho[i][j] * psi[j]
+		}
+	}
+	return real(f)
+}
+
+// BlochFromDensity converts a single-qubit density matrix to spherical Bloch coordinates.
+func BlochFromDensity(rho [2][2]complex128) qubit.BlochState {
+	x := 2 * real(rho[0][1])
+	y := -2 * imag(rho[0][1])
+	z := real(rho[0][0]) - real(rho[1][1])
+
+	theta := math.Atan2(math.Hypot(x, y), z)
+	phi := 0.0
+	if math.Hypot(x, y) > 1e-12 {
+		phi = math.Atan2(y, x)
+		if phi < 0 {
+			phi += 2 * math.Pi
+		}
+	}
+	return qubit.BlochState{Theta: theta, Phi: phi, Radius: math.Min(math.Sqrt(x*x+y*y+z*z), 1)}
+}
@@ -0,0 +1,121 @@
+package quantum
+
+import (
+	"math"
+	"testing"
+
+	"quantum-teleport/internal/domain/qubit"
+)
+
+func TestPrepareMatchesBlochAngles(t *testing.T) {
+	target := qubit.BlochState{Theta: 1.1, Phi: 2.3}
+	register := NewStateVector(1)
+	register.Apply(Prepare(target), 0)
+
+	got := register.Bloch(0)
+	if math.Abs(got.Theta-target.Theta) > 1e-9 || math.Abs(got.Phi-target.Phi) > 1e-9 {
+		t.Fatalf("expected bloch %+v, got %+v", target, got)
+	}
+}
+
+func TestBellPairReducedStateIsMaximallyMixed(t *testing.T) {
+	register := NewStateVector(2)
+	register.Apply(H, 0)
+	register.CNOT(0, 1)
+
+	rho := register.Reduced(1)
+	if math.Abs(real(rho[0][0])-0.5) > 1e-9 || math.Abs(real(rho[1][1])-0.5) > 1e-9 {
+		t.Fatalf("expected maximally mixed diagonal, got %v", rho)
+	}
+	if rho[0][1] != 0 || rho[1][0] != 0 {
+		t.Fatalf("expected vanishing coherences, got %v", rho)
+	}
+}
+
+func TestMeasureCollapsesEntangledPartner(t *testing.T) {
+	register := NewStateVector(2)
+	register.Apply(H, 0)
+	register.CNOT(0, 1)
+
+	bit := register.Measure(0, 0.9)
+	if bit != 0 {
+		t.Fatalf("expected draw above probability to yield 0, got %d", bit)
+	}
+	if p := register.Probability(1); p > 1e-9 {
+		t.Fatalf("expected partner collapsed to |0>, got P(1)=%f", p)
+	}
+}
+
+func TestMeasurePairFollowsBornRule(t *testing.T) {
+	register := NewStateVector(2)
+	register.Apply(Prepare(qubit.BlochState{Theta: math.Pi / 3}), 1)
+
+	probs := register.JointProbabilities(0, 1)
+	if math.Abs(probs[0]-0.75) > 1e-9 || math.Abs(probs[1]-0.25) > 1e-9 {
+		t.Fatalf("unexpected joint probabilities %v", probs)
+	}
+
+	a, b, p := register.MeasurePair(0, 1, 0.8)
+	if a != 0 || b != 1 || math.Abs(p-0.25) > 1e-9 {
+		t.Fatalf("expected outcome 01 with p=0.25, got %d%d p=%f", a, b, p)
+	}
+	if got := register.Probability(1); math.Abs(got-1) > 1e-9 {
+		t.Fatalf("expected qubit 1 collapsed to |1>, got P(1)=%f", got)
+	}
+}
+
+func TestSnapshotRestoresIndependentCopy(t *testing.T) {
+	pure := NewStateVector(2)
+	pure.Apply(H, 0)
+	pure.CNOT(0, 1)
+
+	for _, reg := range []Register{pure, DensityFromStateVector(pure)} {
+		restored, err := Capture(reg).Restore()
+		if err != nil {
+			t.Fatalf("expected snapshot to restore, got %v", err)
+		}
+		reg.Apply(X, 1)
+		if got := restored.JointProbabilities(0, 1); math.Abs(got[0]-0.5) > 1e-9 || math.Abs(got[3]-0.5) > 1e-9 {
+			t.Fatalf("expected restored bell pair to be unaffected, got %v", got)
+		}
+	}
+}
+
+func TestBellFidelityScoresPairsWithoutDisturbingThem(t *testing.T) {
+	pure := NewStateVector(3)
+	pure.Apply(H, 0)
+	pure.CNOT(0, 2)
+	if got := BellFidelity(pure, 0, 2); math.Abs(got-1) > 1e-9 {
+		t.Fatalf("expected a Φ+ pair to score 1, got %f", got)
+	}
+	if got := BellFidelity(pure, 0, 1); math.Abs(got-0.25) > 1e-9 {
+		t.Fatalf("expected qubits outside the pair to score 1/4, got %f", got)
+	}
+	pure.Apply(Z, 2)
+	if got := BellFidelity(DensityFromStateVector(pure), 0, 2); math.Abs(got) > 1e-9 {
+		t.Fatalf("expected Φ- to be orthogonal to Φ+, got %f", got)
+	}
+	if got := pure.Probability(0); math.Abs(got-0.5) > 1e-9 {
+		t.Fatalf("expected the probe to leave the register untouched, got P(1)=%f", got)
+	}
+}
+
+func TestResetRefusesAnUnmeasuredQubit(t *testing.T) {
+	register := NewStateVector(2)
+	register.Apply(H, 0)
+	register.CNOT(0, 1)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected resetting half of a Bell pair to panic")
+			}
+		}()
+		register.Reset(0)
+	}()
+
+	register.Measure(0, 0.1)
+	register.Reset(0)
+	if register.Probability(0) != 0 || math.Abs(register.Probability(1)-1) > 1e-9 {
+		t.Fatalf("expected the measured qubit reset and its partner left at |1>, got P0=%f P1=%f", register.Probability(0), register.Probability(1))
+	}
+}
@@ -1,24 +1,112 @@
 package qubit
 
-// Role enumerates a participant in the teleportation protocol.
+import (
+	"errors"
+	"math"
+	"math/cmplx"
+	"strconv"
+	"strings"
+)
+
+// Role enumerates a participant in a session protocol.
 type Role string
 
 const (
 	RoleAlice Role = "alice"
 	RoleBob   Role = "bob"
+	// RoleCharlie relays entanglement between Alice and Bob in entanglement swapping.
+	RoleCharlie Role = "charlie"
+	// RoleEve is the optional eavesdropper of a session that configures one.
+	RoleEve Role = "eve"
+	// RoleObserver watches a session without owning a qubit or acting on steps.
+	RoleObserver Role = "observer"
+	// RoleInstructor sees the full session state, including hidden qubits.
+	RoleInstructor Role = "instructor"
 )
 
+// RelayRole names relay node i, counted from 1, of a multi-hop teleportation chain.
+func RelayRole(i int) Role {
+	return Role("relay" + strconv.Itoa(i))
+}
+
+// RelayIndex returns the node number of a relay role and whether r is one.
+func RelayIndex(r Role) (int, bool) {
+	rest, ok := strings.CutPrefix(string(r), "relay")
+	if !ok {
+		return 0, false
+	}
+	i, err := strconv.Atoi(rest)
+	if err != nil || i < 1 || rest != strconv.Itoa(i) {
+		return 0, false
+	}
+	return i, true
+}
+
 // BlochState stores spherical coordinates of a qubit on the Bloch sphere.
-// Angles are expressed in radians.
+// Angles are expressed in radians. Radius is the Bloch vector length: 1 for
+// pure states and below 1 for mixed states drawn inside the sphere.
 type BlochState struct {
-	Theta float64 `json:"theta"`
-	Phi   float64 `json:"phi"`
+	Theta  float64 `json:"theta"`
+	Phi    float64 `json:"phi"`
+	Radius float64 `json:"radius"`
 }
 
 // Qubit describes a simplified qubit within the visualizer.
+// Hidden marks a qubit whose Bloch vector was withheld from the viewer.
 type Qubit struct {
-	ID    string     `json:"id"`
-	Role  Role       `json:"role"`
-	State string     `json:"state"`
-	Bloch BlochState `json:"bloch"`
+	ID     string     `json:"id"`
+	Role   Role       `json:"role"`
+	State  string     `json:"state"`
+	Bloch  BlochState `json:"bloch"`
+	Hidden bool       `json:"hidden,omitempty"`
+}
+
+// Preset returns the Bloch coordinates of a named basis state: 0, 1, +, - or i.
+// Ket notation such as "|+>" is accepted as well.
+func Preset(name string) (BlochState, bool) {
+	name = strings.TrimSuffix(strings.TrimPrefix(strings.TrimSpace(name), "|"), ">")
+	switch name {
+	case "0":
+		return BlochState{Theta: 0, Phi: 0, Radius: 1}, true
+	case "1":
+		return BlochState{Theta: math.Pi, Phi: 0, Radius: 1}, true
+	case "+":
+		return BlochState{Theta: math.Pi / 2, Phi: 0, Radius: 1}, true
+	case "-":
+		return BlochState{Theta: math.Pi / 2, Phi: math.Pi, Radius: 1}, true
+	case "i":
+		return BlochState{Theta: math.Pi / 2, Phi: math.Pi / 2, Radius: 1}, true
+	default:
+		return BlochState{}, false
+	}
+}
+
+// BlochFromAmplitudes converts alpha|0> + beta|1> to Bloch coordinates.
+// The amplitudes are normalised and the global phase is discarded.
+func BlochFromAmplitudes(alpha, beta complex128) (BlochState, error) {
+	norm := math.Sqrt(real(alpha)*real(alpha) + imag(alpha)*imag(alpha) + real(beta)*real(beta) + imag(beta)*imag(beta))
+	if norm == 0 || math.IsNaN(norm) || math.IsInf(norm, 0) {
+		return BlochState{}, errors.New("amplitudes must be finite and non-zero")
+	}
+	theta := 2 * math.Acos(math.Min(cmplx.Abs(alpha)/norm, 1))
+	phi := 0.0
+	if cmplx.Abs(alpha) > 1e-12 && cmplx.Abs(beta) > 1e-12 {
+		phi = cmplx.Phase(beta) - cmplx.Phase(alpha)
+	}
+	return NewBlochState(theta, phi)
+}
+
+// NewBlochState validates polar angles and normalises the phase to [0, 2π).
+func NewBlochState(theta, phi float64) (BlochState, error) {
+	if math.IsNaN(theta) || math.IsNaN(phi) || math.IsInf(phi, 0) {
+		return BlochState{}, errors.New("angles must be finite")
+	}
+	if theta < 0 || theta > math.Pi {
+		return BlochState{}, errors.New("theta must be within [0, π]")
+	}
+	phi = math.Mod(phi, 2*math.Pi)
+	if phi < 0 {
+		phi += 2 * math.Pi
+	}
+	return BlochState{Theta: theta, Phi: phi, Radius: 1}, nil
 }
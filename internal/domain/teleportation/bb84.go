@@ -0,0 +1,69 @@
+package teleportation
+
+// ProtocolBB84 names BB84 quantum key distribution: Alice sends qubits in
+// random bases, Bob measures them in random bases and both keep the bits
+// where the bases agreed.
+const ProtocolBB84 = "bb84"
+
+// Steps of BB84; the protocol measures on StepMeasure and ends with StepComplete.
+// StepIntercept only exists when an eavesdropper was configured.
+const (
+	StepPrepare   Step = "prepare"
+	StepIntercept Step = "intercept"
+	StepSift      Step = "sift"
+	StepEstimate  Step = "estimate"
+)
+
+// BB84 session sizes: the number of qubits sent when the config leaves it
+// open and the most a session may send.
+const (
+	DefaultBB84Qubits = 16
+	MaxBB84Qubits     = 64
+)
+
+// BB84AbortQBER is the error rate above which Alice and Bob discard the key:
+// beyond it intercept-resend or noise may have leaked too much to distil it.
+const BB84AbortQBER = 0.11
+
+// Bases of a BB84 qubit: rectilinear {|0>,|1>} and diagonal {|+>,|->}.
+const (
+	BasisRectilinear = '+'
+	BasisDiagonal    = 'x'
+)
+
+// KeyExchange is the record of a BB84 run. Bits and bases are strings with
+// one character per qubit, bases written with BasisRectilinear and
+// BasisDiagonal. Positions index the qubits Alice sent.
+type KeyExchange struct {
+	AliceBits  string `json:"aliceBits,omitempty"`
+	AliceBases string `json:"aliceBases,omitempty"`
+	EveBits    string `json:"eveBits,omitempty"`
+	EveBases   string `json:"eveBases,omitempty"`
+	BobBits    string `json:"bobBits,omitempty"`
+	BobBases   string `json:"bobBases,omitempty"`
+	// Sifted lists the positions where Alice's and Bob's bases agreed.
+	Sifted []int `json:"sifted,omitempty"`
+	// Sample lists the sifted positions whose bits were disclosed to estimate
+	// the error rate; they are not part of the key.
+	Sample []int `json:"sample,omitempty"`
+	KeyReport
+}
+
+// KeyReport is the public outcome of a BB84 run, announced over the classical
+// channel and safe to show to everyone.
+type KeyReport struct {
+	Sent   int `json:"sent"`
+	Sifted int `json:"siftedCount"`
+	Errors int `json:"errors"`
+	// QBER is the share of sample positions where Alice's and Bob's bits differ.
+	QBER      float64 `json:"qber"`
+	KeyLength int     `json:"keyLength"`
+	Aborted   bool    `json:"aborted"`
+}
+
+// Clone returns a copy that shares no slices with k.
+func (k KeyExchange) Clone() *KeyExchange {
+	k.Sifted = append([]int(nil), k.Sifted...)
+	k.Sample = append([]int(nil), k.Sample...)
+	return &k
+}
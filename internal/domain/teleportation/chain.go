@@ -0,0 +1,41 @@
+package teleportation
+
+import "quantum-teleport/internal/domain/qubit"
+
+// ProtocolChain names multi-hop teleportation: the unknown state travels from
+// Alice to Bob through Config.Relays intermediate nodes, each hop a full
+// teleportation between neighbours, the way a repeater chain forwards it.
+const ProtocolChain = "chain"
+
+// MaxChainRelays bounds the number of relay nodes a chain session may have.
+const MaxChainRelays = 8
+
+// Hop is one link of a teleportation chain: Index counts from 1 and From
+// sends the state To the next node.
+type Hop struct {
+	Index int        `json:"index"`
+	From  qubit.Role `json:"from"`
+	To    qubit.Role `json:"to"`
+}
+
+// HopResult archives a finished hop with the bits measured on it and the
+// receiver's correction. Its fidelity is scored against the original unknown
+// state, so errors picked up on earlier hops show in later ones.
+type HopResult struct {
+	Hop
+	Measurement Measurement       `json:"measurement"`
+	Correction  CorrectionAttempt `json:"correction"`
+}
+
+// ChainNode returns the role of node i of a chain with the given number of
+// relays: Alice is node 0, Bob node relays+1 and relay i sits in between.
+func ChainNode(i, relays int) qubit.Role {
+	switch {
+	case i == 0:
+		return qubit.RoleAlice
+	case i > relays:
+		return qubit.RoleBob
+	default:
+		return qubit.RelayRole(i)
+	}
+}
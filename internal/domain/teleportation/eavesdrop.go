@@ -0,0 +1,35 @@
+package teleportation
+
+// Interception records Eve's hold on Alice's classical bits in transit: she
+// read them and may have flipped some before passing them on to Bob.
+type Interception struct {
+	// Flip marks the flipped bits in transmission order, "00" when Eve only read them.
+	Flip      string      `json:"flip"`
+	Delivered Measurement `json:"delivered"`
+	// FidelityDrop is how much of the state Bob loses by correcting for the
+	// delivered bits instead of Alice's.
+	FidelityDrop float64 `json:"fidelityDrop"`
+}
+
+// NewInterception flips the bits of m marked in flip, such as "01" for M2.
+func NewInterception(m Measurement, flip string) (Interception, bool) {
+	if len(flip) != 2 {
+		return Interception{}, false
+	}
+	var mask [2]int
+	for i, b := range flip {
+		if b != '0' && b != '1' {
+			return Interception{}, false
+		}
+		mask[i] = int(b - '0')
+	}
+	return Interception{
+		Flip:      flip,
+		Delivered: NewMeasurement(m.M1^mask[0], m.M2^mask[1], m.Probability),
+	}, true
+}
+
+// Tampered reports whether Eve changed any bit.
+func (i Interception) Tampered() bool {
+	return i.Flip != "00"
+}
@@ -0,0 +1,260 @@
+package teleportation
+
+import (
+	"strconv"
+	"time"
+
+	"quantum-teleport/internal/domain/qubit"
+)
+
+// EventType names a fact recorded in a session's event stream.
+type EventType string
+
+const (
+	EventSessionCreated    EventType = "session_created"
+	EventRoleJoined        EventType = "role_joined"
+	EventStepAdvanced      EventType = "step_advanced"
+	EventMeasurementTaken  EventType = "measurement_taken"
+	EventBitsSent          EventType = "bits_sent"
+	EventCorrectionApplied EventType = "correction_applied"
+	EventRoleLeft          EventType = "role_left"
+	EventHostAction        EventType = "host_action"
+	EventBitsEncoded       EventType = "bits_encoded"
+	EventQubitsIntercepted EventType = "qubits_intercepted"
+	EventQubitsMeasured    EventType = "qubits_measured"
+	EventBasesCompared     EventType = "bases_compared"
+	EventKeyEstimated      EventType = "key_estimated"
+	EventBitsIntercepted   EventType = "bits_intercepted"
+	EventBitsTampered      EventType = "bits_tampered"
+	EventControlMeasured   EventType = "control_measured"
+	EventSharesMeasured    EventType = "shares_measured"
+	EventSharesSifted      EventType = "shares_sifted"
+	EventSharesCombined    EventType = "shares_combined"
+)
+
+// FollowsStep reports whether events of type t are raised by entering a step
+// rather than by a participant action of their own.
+func (t EventType) FollowsStep() bool {
+	switch t {
+	case EventMeasurementTaken, EventBitsSent, EventBitsIntercepted,
+		EventQubitsIntercepted, EventQubitsMeasured, EventBasesCompared, EventKeyEstimated,
+		EventControlMeasured, EventSharesMeasured, EventSharesSifted, EventSharesCombined:
+		return true
+	default:
+		return false
+	}
+}
+
+// Reasons a role was freed, carried by EventRoleLeft.
+const (
+	LeaveVoluntary = "leave"
+	LeaveTimeout   = "timeout"
+	LeaveKicked    = "kick"
+)
+
+// Event is one entry of the append-only session history. Actor is the role
+// that caused it and is empty for events the server raised on its own.
+type Event struct {
+	Seq     int          `json:"seq"`
+	Type    EventType    `json:"type"`
+	At      time.Time    `json:"at"`
+	Actor   qubit.Role   `json:"actor,omitempty"`
+	Payload EventPayload `json:"payload"`
+}
+
+// EventPayload holds the data of an event; only the fields its type needs are set.
+// Step is the step index the session is on once the event is applied.
+type EventPayload struct {
+	Step         int                `json:"step"`
+	Title        string             `json:"title,omitempty"`
+	Role         qubit.Role         `json:"role,omitempty"`
+	Reason       string             `json:"reason,omitempty"`
+	Action       string             `json:"action,omitempty"`
+	Measurement  *Measurement       `json:"measurement,omitempty"`
+	Correction   *CorrectionAttempt `json:"correction,omitempty"`
+	Encoding     *Encoding          `json:"encoding,omitempty"`
+	Interception *Interception      `json:"interception,omitempty"`
+	Control      *Control           `json:"control,omitempty"`
+	// Key is the public outcome of a BB84 run so far.
+	Key *KeyReport `json:"key,omitempty"`
+	// Sharing is the public outcome of a secret-sharing run so far.
+	Sharing *SharingReport `json:"sharing,omitempty"`
+	// Config is the creation config, seed included, carried by session_created.
+	Config *Config `json:"config,omitempty"`
+	// State is the unknown state prepared by session_created or a regenerate.
+	State *qubit.BlochState `json:"state,omitempty"`
+}
+
+// Record stamps the event, appends it to the stream and refreshes the
+// human-readable log derived from the stream.
+func (s *SessionState) Record(e Event) {
+	e.Seq = len(s.Events) + 1
+	if e.At.IsZero() {
+		e.At = time.Now()
+	}
+	s.Events = append(s.Events, e)
+	s.Log = RenderLog(s.Events)
+}
+
+// RenderLog turns an event stream into the session log. A host rewind or
+// reset drops the lines written after the target step was entered, matching
+// the snapshot the session itself returns to.
+func RenderLog(events []Event) []string {
+	var lines []string
+	// entered maps a step index to the log length once that step was entered.
+	entered := make(map[int]int)
+	for _, e := range events {
+		if e.Type == EventHostAction {
+			switch e.Payload.Action {
+			case "reset", "rewind":
+				if n, ok := entered[e.Payload.Step]; ok {
+					lines = lines[:n]
+				}
+				forgetAfter(entered, e.Payload.Step)
+			case "regenerate":
+				forgetAfter(entered, 0)
+				entered[0] = len(lines)
+			}
+		}
+		lines = append(lines, Describe(e))
+		if e.Type == EventSessionCreated || e.Type == EventStepAdvanced || e.Type.FollowsStep() {
+			entered[e.Payload.Step] = len(lines)
+		}
+	}
+	return lines
+}
+
+// percent renders a share such as 0.125 as "12.5%".
+func percent(share float64) string {
+	return strconv.FormatFloat(100*share, 'f', 1, 64) + "%"
+}
+
+func forgetAfter(entered map[int]int, step int) {
+	for k := range entered {
+		if k > step {
+			delete(entered, k)
+		}
+	}
+}
+
+// Describe renders a single event as a log line.
+func Describe(e Event) string {
+	p := e.Payload
+	switch e.Type {
+	case EventSessionCreated:
+		return "Сессия создана, роли свободны."
+	case EventRoleJoined:
+		return "Роль закреплена: " + string(p.Role)
+	case EventStepAdvanced:
+		return "Шаг: " + p.Title
+	case EventMeasurementTaken:
+		switch {
+		case e.Actor == qubit.RoleBob:
+			// The log is public, so the decoded bits stay out of it like the
+			// encoded ones; Bob's qubit shows them once the run is complete.
+			return "Боб выполнил измерение Белла и прочитал два бита"
+		case e.Actor == qubit.RoleCharlie:
+			return "Чарли выполнил измерение Белла"
+		}
+		if i, ok := qubit.RelayIndex(e.Actor); ok {
+			return "Узел " + strconv.Itoa(i) + " выполнил измерение Белла"
+		}
+		return "Алиса выполнила измерение Белла"
+	case EventBitsEncoded:
+		// The log is public, so the bits stay out of it until Bob decodes them.
+		return "Алиса закодировала два бита в свою половину пары"
+	case EventBitsSent:
+		// Chain hops name their receiver; every other protocol sends to Bob.
+		to := "Бобу"
+		if i, ok := qubit.RelayIndex(p.Role); ok {
+			to = "узлу " + strconv.Itoa(i)
+		}
+		if p.Measurement == nil {
+			return "Классические биты отправлены " + to
+		}
+		return "Классические биты отправлены " + to + ": " + p.Measurement.Bits()
+	case EventCorrectionApplied:
+		who := "Боб"
+		if i, ok := qubit.RelayIndex(e.Actor); ok {
+			who = "Узел " + strconv.Itoa(i)
+		}
+		if p.Correction == nil {
+			return who + " применил коррекцию"
+		}
+		return who + " применил коррекцию " + string(p.Correction.Applied) + ", точность " + strconv.FormatFloat(p.Correction.Fidelity, 'f', 2, 64)
+	case EventBitsIntercepted:
+		// The log is public: the bits Eve read show once they reach Bob.
+		return "Ева перехватила классические биты"
+	case EventBitsTampered:
+		// The same line for a flip and for "00": whether Eve changed the bits
+		// is revealed through the interception once the run is complete.
+		return "Ева решила, какие биты передать Бобу"
+	case EventControlMeasured:
+		if p.Control == nil {
+			return "Чарли измерил свой кубит в базисе X"
+		}
+		return "Чарли измерил свой кубит в базисе X и сообщил Бобу бит " + strconv.Itoa(p.Control.Bit)
+	case EventSharesMeasured:
+		if p.Sharing == nil {
+			return "Алиса, Боб и Чарли измерили GHZ-тройки"
+		}
+		return "Алиса, Боб и Чарли измерили " + strconv.Itoa(p.Sharing.Rounds) + " GHZ-троек в случайных базисах X и Y"
+	case EventSharesSifted:
+		if p.Sharing == nil {
+			return "Базисы объявлены"
+		}
+		return "Базисы объявлены: годны " + strconv.Itoa(p.Sharing.ValidCount) + " из " + strconv.Itoa(p.Sharing.Rounds) + " раундов"
+	case EventSharesCombined:
+		if p.Sharing == nil {
+			return "Боб и Чарли объединили доли"
+		}
+		return "Боб и Чарли объединили доли: ключ " + strconv.Itoa(p.Sharing.KeyLength) + " бит, ошибок " + strconv.Itoa(p.Sharing.Errors) +
+			"; поодиночке совпадение с Алисой " + percent(p.Sharing.BobAlone) + " у Боба и " + percent(p.Sharing.CharlieAlone) + " у Чарли"
+	case EventQubitsIntercepted:
+		return "Ева перехватила кубиты, измерила их и отправила Бобу заново"
+	case EventQubitsMeasured:
+		if p.Key == nil {
+			return "Боб измерил кубиты в случайных базисах"
+		}
+		return "Боб измерил " + strconv.Itoa(p.Key.Sent) + " кубитов в случайных базисах"
+	case EventBasesCompared:
+		if p.Key == nil {
+			return "Алиса и Боб сравнили базисы"
+		}
+		return "Алиса и Боб сравнили базисы: совпали " + strconv.Itoa(p.Key.Sifted) + " из " + strconv.Itoa(p.Key.Sent)
+	case EventKeyEstimated:
+		if p.Key == nil {
+			return "Алиса и Боб оценили долю ошибок"
+		}
+		qber := percent(p.Key.QBER)
+		if p.Key.Aborted {
+			return "Доля ошибок " + qber + " выше порога, ключ отброшен"
+		}
+		return "Доля ошибок " + qber + ", длина ключа " + strconv.Itoa(p.Key.KeyLength) + " бит"
+	case EventRoleLeft:
+		switch p.Reason {
+		case LeaveTimeout:
+			return "Роль освобождена по таймауту: " + string(p.Role)
+		case LeaveKicked:
+			return "Ведущий освободил роль: " + string(p.Role)
+		default:
+			return "Роль освобождена: " + string(p.Role)
+		}
+	case EventHostAction:
+		switch p.Action {
+		case "reset":
+			return "Ведущий сбросил сессию"
+		case "rewind":
+			return "Ведущий вернул сессию к шагу: " + p.Title
+		case "regenerate":
+			return "Ведущий сгенерировал новое неизвестное состояние"
+		case "lock":
+			return "Ведущий закрыл вход в сессию"
+		case "unlock":
+			return "Ведущий открыл вход в сессию"
+		}
+		return "Ведущий: " + p.Action
+	default:
+		return string(e.Type)
+	}
+}
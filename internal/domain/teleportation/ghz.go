@@ -0,0 +1,87 @@
+package teleportation
+
+// Protocols built on a three-qubit GHZ state (|000>+|111>)/√2 shared by
+// Alice, Bob and Charlie. ProtocolControlled is controlled teleportation:
+// Bob recovers Alice's state only once Charlie measures his qubit and tells
+// him the result. ProtocolSecretSharing is GHZ secret sharing: Alice's key
+// bits can only be read by Bob and Charlie together.
+const (
+	ProtocolControlled    = "controlled"
+	ProtocolSecretSharing = "secret_sharing"
+)
+
+// StepControl is the controlled-teleportation step on which Charlie measures
+// his GHZ qubit in the X basis and announces the bit.
+const StepControl Step = "control"
+
+// Bases of GHZ secret sharing; BasisDiagonal doubles as the X basis.
+const BasisY = 'y'
+
+// Secret-sharing session sizes: the number of GHZ triples shared when the
+// config leaves Qubits open and the most a session may share.
+const (
+	DefaultSharingRounds = 16
+	MaxSharingRounds     = 64
+)
+
+// Control records Charlie's part in controlled teleportation.
+type Control struct {
+	Bit         int     `json:"bit"`
+	Probability float64 `json:"probability"`
+	// FidelityWithout is the best Bob could do with Alice's bits alone,
+	// scored just before Charlie measured.
+	FidelityWithout float64 `json:"fidelityWithout"`
+	// Correction is what Alice's and Charlie's bits dictate together.
+	Correction Correction `json:"correction"`
+}
+
+// ControlledCorrection returns Bob's correction for Alice's bits and Charlie's
+// bit: Charlie's X-basis outcome adds a phase flip to the one M1 controls.
+func ControlledCorrection(m Measurement, bit int) Correction {
+	return pauliForBits[2*(m.M1^bit)+m.M2]
+}
+
+// SecretSharing is the record of a GHZ secret-sharing run. Bits and bases hold
+// one character per round, bases written with BasisDiagonal for X and BasisY.
+type SecretSharing struct {
+	AliceBits    string `json:"aliceBits,omitempty"`
+	AliceBases   string `json:"aliceBases,omitempty"`
+	BobBits      string `json:"bobBits,omitempty"`
+	BobBases     string `json:"bobBases,omitempty"`
+	CharlieBits  string `json:"charlieBits,omitempty"`
+	CharlieBases string `json:"charlieBases,omitempty"`
+	// Valid lists the rounds with an even number of Y bases, the only ones
+	// whose outcomes are correlated.
+	Valid []int `json:"valid,omitempty"`
+	SharingReport
+}
+
+// SharingReport is the public outcome of a secret-sharing run.
+type SharingReport struct {
+	Rounds     int `json:"rounds"`
+	ValidCount int `json:"validCount"`
+	// Errors counts valid rounds where Bob's and Charlie's bits together do
+	// not give Alice's.
+	Errors int `json:"errors"`
+	// BobAlone and CharlieAlone are the shares of valid rounds where one
+	// party's bit on its own equals Alice's: about one half, a coin toss.
+	BobAlone     float64 `json:"bobAlone"`
+	CharlieAlone float64 `json:"charlieAlone"`
+	KeyLength    int     `json:"keyLength"`
+}
+
+// Clone returns a copy that shares no slices with s.
+func (s SecretSharing) Clone() *SecretSharing {
+	s.Valid = append([]int(nil), s.Valid...)
+	return &s
+}
+
+// SharedBit returns the bit Bob and Charlie reconstruct in valid round i: the
+// parity of their bits, inverted when two of the three bases were Y.
+func (s SecretSharing) SharedBit(i int) byte {
+	bit := (s.BobBits[i] - '0') ^ (s.CharlieBits[i] - '0')
+	if s.AliceBases[i] != s.BobBases[i] || s.BobBases[i] != s.CharlieBases[i] {
+		bit ^= 1
+	}
+	return '0' + bit
+}
@@ -0,0 +1,75 @@
+package teleportation
+
+import (
+	"errors"
+
+	"quantum-teleport/internal/domain/quantum"
+	"quantum-teleport/internal/domain/qubit"
+)
+
+// StepSnapshot is an immutable copy of the session as it was on entering a step.
+type StepSnapshot struct {
+	StepIndex   int                `json:"stepIndex"`
+	Qubits      []qubit.Qubit      `json:"qubits"`
+	Log         []string           `json:"log"`
+	Measurement *Measurement       `json:"measurement,omitempty"`
+	Correction  *CorrectionAttempt `json:"correction,omitempty"`
+	ProtocolState
+	Register quantum.Snapshot `json:"register"`
+}
+
+// Checkpoint records the current step in History, replacing any snapshot
+// already kept for it or for a later step.
+func (s *SessionState) Checkpoint() {
+	snap := StepSnapshot{
+		StepIndex:     s.StepIndex,
+		Qubits:        append([]qubit.Qubit(nil), s.Qubits...),
+		ProtocolState: s.ProtocolState.clone(),
+		Register:      quantum.Capture(s.Register),
+	}
+	if s.Measurement != nil {
+		m := *s.Measurement
+		snap.Measurement = &m
+	}
+	if s.Correction != nil {
+		c := *s.Correction
+		snap.Correction = &c
+	}
+	history := s.History[:0:0]
+	for _, h := range s.History {
+		if h.StepIndex < s.StepIndex {
+			history = append(history, h)
+		}
+	}
+	s.History = append(history, snap)
+}
+
+// Rewind restores the snapshot taken on entering step and drops the later ones.
+// The log follows from the event that records the rewind, see RenderLog.
+func (s *SessionState) Rewind(step int) error {
+	for i, snap := range s.History {
+		if snap.StepIndex != step {
+			continue
+		}
+		register, err := snap.Register.Restore()
+		if err != nil {
+			return err
+		}
+		s.StepIndex = snap.StepIndex
+		s.Qubits = append([]qubit.Qubit(nil), snap.Qubits...)
+		s.ProtocolState = snap.ProtocolState.clone()
+		s.Measurement, s.Correction = nil, nil
+		if snap.Measurement != nil {
+			m := *snap.Measurement
+			s.Measurement = &m
+		}
+		if snap.Correction != nil {
+			c := *snap.Correction
+			s.Correction = &c
+		}
+		s.Register = register
+		s.History = s.History[:i+1]
+		return nil
+	}
+	return errors.New("no snapshot for step")
+}
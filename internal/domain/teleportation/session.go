@@ -1,9 +1,12 @@
 package teleportation
 
 import (
+	"strconv"
 	"time"
 
+	"quantum-teleport/internal/domain/quantum"
 	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/pkg/utils"
 )
 
 // Step represents a stage of the teleportation protocol.
@@ -23,6 +26,8 @@ type StepInfo struct {
 	Key         Step   `json:"key"`
 	Title       string `json:"title"`
 	Description string `json:"description"`
+	// Hop is the chain link the step belongs to in multi-hop teleportation.
+	Hop *Hop `json:"hop,omitempty"`
 }
 
 // Participant describes a bound client role within a session lobby.
@@ -34,16 +39,195 @@ type Participant struct {
 	LastSeen  time.Time  `json:"-"`
 }
 
+// Observer is a read-only spectator admitted to a session by token.
+type Observer struct {
+	Token    string    `json:"-"`
+	JoinedAt time.Time `json:"joinedAt"`
+}
+
+// BellOutcome names the Bell state Alice's measurement projected onto.
+type BellOutcome string
+
+const (
+	BellPhiPlus  BellOutcome = "phi+"
+	BellPsiPlus  BellOutcome = "psi+"
+	BellPhiMinus BellOutcome = "phi-"
+	BellPsiMinus BellOutcome = "psi-"
+)
+
+// Correction names the Pauli operation Bob applies to his qubit, in order of application.
+type Correction string
+
+const (
+	CorrectionI  Correction = "I"
+	CorrectionX  Correction = "X"
+	CorrectionZ  Correction = "Z"
+	CorrectionXZ Correction = "XZ"
+)
+
+// Valid reports whether the correction is one of the four Pauli choices.
+func (c Correction) Valid() bool {
+	switch c {
+	case CorrectionI, CorrectionX, CorrectionZ, CorrectionXZ:
+		return true
+	default:
+		return false
+	}
+}
+
+// Measurement records the classical result of Alice's Bell measurement.
+// M1 comes from the unknown qubit and controls Z, M2 from Alice's half of the
+// pair and controls X.
+type Measurement struct {
+	M1          int         `json:"m1"`
+	M2          int         `json:"m2"`
+	Outcome     BellOutcome `json:"outcome"`
+	Probability float64     `json:"probability"`
+	Correction  Correction  `json:"correction"`
+}
+
+// pauliForBits maps two bits, indexed as 2*m1 + m2, to the Pauli operation
+// they control: the first bit controls Z, the second X.
+var pauliForBits = [4]Correction{CorrectionI, CorrectionX, CorrectionZ, CorrectionXZ}
+
+// NewMeasurement builds a measurement record from the two observed bits.
+func NewMeasurement(m1, m2 int, probability float64) Measurement {
+	outcomes := [4]BellOutcome{BellPhiPlus, BellPsiPlus, BellPhiMinus, BellPsiMinus}
+	k := 2*m1 + m2
+	return Measurement{M1: m1, M2: m2, Outcome: outcomes[k], Probability: probability, Correction: pauliForBits[k]}
+}
+
+// Bits renders the classical bits in transmission order.
+func (m Measurement) Bits() string {
+	return strconv.Itoa(m.M1) + strconv.Itoa(m.M2)
+}
+
+// CorrectionAttempt records Bob's latest correction choice and how well it restored the state.
+type CorrectionAttempt struct {
+	Applied  Correction `json:"applied"`
+	Correct  bool       `json:"correct"`
+	Fidelity float64    `json:"fidelity"`
+	Attempts int        `json:"attempts"`
+}
+
+// NoiseProfile describes the channel applied to every simulated qubit after each step.
+type NoiseProfile struct {
+	Channel     quantum.Channel `json:"channel"`
+	Probability float64         `json:"probability"`
+}
+
+// ProtocolTeleportation names the default protocol sessions run.
+const ProtocolTeleportation = "teleportation"
+
+// Config holds the options a session was created with.
+type Config struct {
+	// Protocol names the protocol the session runs.
+	Protocol string `json:"protocol"`
+	// InitialState fixes the unknown state; nil draws a random one.
+	InitialState *qubit.BlochState `json:"initialState,omitempty"`
+	// Seed drives every random choice and is filled in when left empty.
+	Seed *int64 `json:"seed,omitempty"`
+	// Noise is the channel applied to the simulated qubits, if any.
+	Noise *NoiseProfile `json:"noise,omitempty"`
+	// Relays is the number of intermediate nodes of a chain session.
+	Relays int `json:"relays,omitempty"`
+	// Qubits is the number of BB84 qubits or secret sharing rounds.
+	Qubits int `json:"qubits,omitempty"`
+	// Eavesdropper adds the eve role to teleportation or BB84.
+	Eavesdropper bool `json:"eavesdropper,omitempty"`
+}
+
 // SessionState aggregates the teleportation session status.
 type SessionState struct {
-	ID           string                     `json:"id"`
-	StepIndex    int                        `json:"stepIndex"`
-	Steps        []StepInfo                 `json:"steps"`
-	Qubits       []qubit.Qubit              `json:"qubits"`
+	ID        string        `json:"id"`
+	Config    Config        `json:"config"`
+	StepIndex int           `json:"stepIndex"`
+	Steps     []StepInfo    `json:"steps"`
+	Qubits    []qubit.Qubit `json:"qubits"`
+	// Log is the human-readable rendering of Events.
 	Log          []string                   `json:"log"`
 	Participants map[qubit.Role]Participant `json:"participants"`
+	// Observers holds read-only spectators keyed by token.
+	Observers map[string]Observer `json:"-"`
+	// ObserverCount is the number of spectators admitted to the session.
+	ObserverCount int `json:"observerCount"`
+	// UpdatedAt marks the last activity, used to expire abandoned sessions.
+	UpdatedAt time.Time `json:"updatedAt"`
+	// Measurement holds Alice's classical bits once the Bell measurement happened.
+	Measurement *Measurement `json:"measurement,omitempty"`
+	// Correction holds Bob's chosen Pauli correction and the resulting fidelity.
+	Correction *CorrectionAttempt `json:"correction,omitempty"`
+	// ProtocolState holds what only some protocols track; its fields are
+	// promoted into the session and its JSON.
+	ProtocolState
+	// ActiveHop is the chain link the current step works on; it is filled in
+	// for the views sent to clients.
+	ActiveHop *Hop `json:"activeHop,omitempty"`
 	// HiddenState keeps the original unknown state to restore it after measurement collapse.
 	HiddenState qubit.BlochState `json:"-"`
+	// HostToken authorises the instructor controls of the session.
+	HostToken string `json:"-"`
+	// Locked blocks new participants from joining.
+	Locked bool `json:"locked"`
+	// Register is the simulated three-qubit system behind the displayed qubits:
+	// a state vector for ideal sessions, a density matrix when noise is configured.
+	Register quantum.Register `json:"-"`
+	// Events is the append-only typed history the log is rendered from.
+	Events []Event `json:"-"`
+	// History keeps a snapshot per entered step, oldest first, for rewinding.
+	History []StepSnapshot `json:"-"`
+	// Random is the session's seeded stream for the initial state and measurement draws.
+	Random *utils.SeededRand `json:"-"`
+}
+
+// ProtocolState is the part of a session specific to individual protocols;
+// each protocol sets only its own fields. Snapshots, rewinds and restarts copy
+// or clear it as a whole, so a new protocol only adds its field here and to
+// clone. It is a plain struct rather than an interface so sessions and their
+// snapshots round-trip through the file store.
+type ProtocolState struct {
+	// Encoding holds the bits Alice packed into her qubit in superdense coding.
+	Encoding *Encoding `json:"encoding,omitempty"`
+	// Hops archives the finished hops of a chain session, oldest first.
+	Hops []HopResult `json:"hops,omitempty"`
+	// Interception holds what Eve did with the classical bits in transit.
+	Interception *Interception `json:"interception,omitempty"`
+	// Control holds Charlie's bit and its effect in controlled teleportation.
+	Control *Control `json:"control,omitempty"`
+	// Sharing holds the bases, bits and outcome of GHZ secret sharing.
+	Sharing *SecretSharing `json:"sharing,omitempty"`
+	// KeyExchange holds the bases, bits and error estimate of a BB84 run.
+	KeyExchange *KeyExchange `json:"keyExchange,omitempty"`
+}
+
+// clone returns a copy that shares no pointers or slices with p.
+func (p ProtocolState) clone() ProtocolState {
+	c := ProtocolState{Hops: append([]HopResult(nil), p.Hops...)}
+	if p.Encoding != nil {
+		e := *p.Encoding
+		c.Encoding = &e
+	}
+	if p.Interception != nil {
+		i := *p.Interception
+		c.Interception = &i
+	}
+	if p.Control != nil {
+		k := *p.Control
+		c.Control = &k
+	}
+	if p.Sharing != nil {
+		c.Sharing = p.Sharing.Clone()
+	}
+	if p.KeyExchange != nil {
+		c.KeyExchange = p.KeyExchange.Clone()
+	}
+	return c
+}
+
+// CloneProtocolState returns a copy of the session's protocol state that
+// shares no pointers or slices with it.
+func (s *SessionState) CloneProtocolState() ProtocolState {
+	return s.ProtocolState.clone()
 }
 
 // NextStep advances the session to the next step when possible.
@@ -0,0 +1,38 @@
+package teleportation
+
+// ProtocolSuperdense names superdense coding: Alice sends two classical bits
+// to Bob by transmitting her half of a shared Bell pair.
+const ProtocolSuperdense = "superdense"
+
+// Steps of superdense coding that teleportation does not have; the protocol
+// starts with StepEntangle and ends with StepComplete like teleportation.
+const (
+	StepEncode    Step = "encode"
+	StepSendQubit Step = "send_qubit"
+	StepDecode    Step = "decode"
+)
+
+// Encoding records the two bits Alice chose and the Pauli operation she
+// applied to her half of the pair to encode them.
+type Encoding struct {
+	Bits      string     `json:"bits"`
+	Operation Correction `json:"operation"`
+	Attempts  int        `json:"attempts"`
+}
+
+// NewEncoding parses two bits such as "10" into an encoding. The first bit
+// controls Z and the second X, so Bob's Bell measurement reads them back in
+// the same order.
+func NewEncoding(bits string) (Encoding, bool) {
+	if len(bits) != 2 {
+		return Encoding{}, false
+	}
+	k := 0
+	for _, b := range bits {
+		if b != '0' && b != '1' {
+			return Encoding{}, false
+		}
+		k = 2*k + int(b-'0')
+	}
+	return Encoding{Bits: bits, Operation: pauliForBits[k], Attempts: 1}, true
+}
@@ -0,0 +1,11 @@
+package teleportation
+
+// ProtocolSwapping names entanglement swapping: Charlie's Bell measurement on
+// halves of two separate pairs leaves Alice and Bob entangled although their
+// qubits never interacted.
+const ProtocolSwapping = "swapping"
+
+// StepDistribute is the entanglement-swapping step on which Alice and Charlie
+// share one Bell pair and Charlie and Bob another. The protocol continues with
+// StepMeasure, StepSend, StepReconstruct and StepComplete.
+const StepDistribute Step = "distribute"
@@ -72,6 +72,15 @@ func TestTeleportationEndToEndWithWebsocketBroadcasts(t *testing.T) {
 	waitForStep(t, aliceConn, 4)
 	waitForStep(t, bobConn, 4)
 
+	command := map[string]string{"type": "correct", "correction": string(session.Measurement.Correction)}
+	if err := bobConn.WriteJSON(command); err != nil {
+		t.Fatalf("failed to send correction: %v", err)
+	}
+	corrected := waitForCorrection(t, aliceConn)
+	if !corrected.Global.Correction.Correct {
+		t.Fatalf("expected correction sent over websocket to be correct, got %+v", corrected.Global.Correction)
+	}
+
 	session = advanceSession(t, server.URL, session.ID, bob)
 	if session.StepIndex != len(session.Steps)-1 {
 		t.Fatalf("expected session completion, got %d", session.StepIndex)
@@ -200,6 +209,22 @@ func waitForStep(t *testing.T, conn *websocket.Conn, expectedStep int) service.B
 	}
 }
 
+func waitForCorrection(t *testing.T, conn *websocket.Conn) service.BroadcastMessage {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if err := conn.SetReadDeadline(deadline); err != nil {
+			t.Fatalf("failed to set deadline: %v", err)
+		}
+
+		msg := readMessage(t, conn)
+		if msg.Global != nil && msg.Global.Correction != nil {
+			return msg
+		}
+	}
+}
+
 func readMessage(t *testing.T, conn *websocket.Conn) service.BroadcastMessage {
 	t.Helper()
 
@@ -209,3 +234,222 @@ func readMessage(t *testing.T, conn *websocket.Conn) service.BroadcastMessage {
 	}
 	return msg
 }
+
+func TestWebsocketCommandProtocol(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	application := app.New(logger)
+
+	server := httptest.NewServer(transporthttp.Middleware(application.Routes(), logger))
+	defer server.Close()
+
+	session := createSession(t, server.URL)
+	alice := joinRole(t, server.URL, session.ID, "alice", "")
+	bob := joinRole(t, server.URL, session.ID, "bob", "")
+
+	aliceConn := dialWebsocket(t, server.URL, session.ID, alice)
+	defer aliceConn.Close()
+	expectJoined(t, aliceConn, session.ID, qubit.RoleAlice)
+
+	bobConn := dialWebsocket(t, server.URL, session.ID, bob)
+	defer bobConn.Close()
+	expectJoined(t, bobConn, session.ID, qubit.RoleBob)
+
+	connected := waitForType(t, aliceConn, "info")
+	if connected.Info == nil || connected.Info.Event != service.InfoRoleConnected || connected.Info.Role != qubit.RoleBob {
+		t.Fatalf("expected bob connection info, got %+v", connected.Info)
+	}
+
+	sendCommand(t, bobConn, map[string]string{"id": "b1", "type": "advance"})
+	if ack := waitForType(t, bobConn, "ack"); ack.RequestID != "b1" {
+		t.Fatalf("expected ack for b1, got %+v", ack)
+	}
+	waitForStep(t, aliceConn, 1)
+
+	sendCommand(t, bobConn, map[string]string{"id": "b2", "type": "advance"})
+	rejected := waitForType(t, bobConn, "error")
+	if rejected.RequestID != "b2" || rejected.Error != "role not permitted for step" {
+		t.Fatalf("expected correlated permission error, got %+v", rejected)
+	}
+
+	sendCommand(t, aliceConn, map[string]string{"id": "a1", "type": "annotate", "text": "Смотрите на сферу Боба"})
+	note := waitForType(t, bobConn, "annotation")
+	if note.Annotation == nil || note.Annotation.Role != qubit.RoleAlice || note.Annotation.Text != "Смотрите на сферу Боба" {
+		t.Fatalf("expected alice's annotation, got %+v", note.Annotation)
+	}
+
+	sendCommand(t, aliceConn, map[string]string{"id": "a2", "type": "teleport"})
+	if unknown := waitForType(t, aliceConn, "error"); unknown.RequestID != "a2" {
+		t.Fatalf("expected unknown command error for a2, got %+v", unknown)
+	}
+
+	sendCommand(t, bobConn, map[string]string{"id": "b3", "type": "leave"})
+	left := waitForType(t, aliceConn, "info")
+	for left.Info.Event != service.InfoRoleLeft {
+		left = waitForType(t, aliceConn, "info")
+	}
+	if left.Info.Role != qubit.RoleBob {
+		t.Fatalf("expected bob to leave, got %+v", left.Info)
+	}
+	_ = bobConn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		if _, _, err := bobConn.ReadMessage(); err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+				t.Fatalf("expected normal closure after leave, got %v", err)
+			}
+			break
+		}
+	}
+}
+
+func sendCommand(t *testing.T, conn *websocket.Conn, command map[string]string) {
+	t.Helper()
+
+	if err := conn.WriteJSON(command); err != nil {
+		t.Fatalf("failed to send %s command: %v", command["type"], err)
+	}
+}
+
+func waitForType(t *testing.T, conn *websocket.Conn, messageType string) service.BroadcastMessage {
+	t.Helper()
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if err := conn.SetReadDeadline(deadline); err != nil {
+			t.Fatalf("failed to set deadline: %v", err)
+		}
+
+		msg := readMessage(t, conn)
+		if msg.Type == messageType {
+			return msg
+		}
+	}
+}
+
+func TestObserverFollowsSessionOverWebsocket(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	application := app.New(logger)
+
+	server := httptest.NewServer(transporthttp.Middleware(application.Routes(), logger))
+	defer server.Close()
+
+	session := createSession(t, server.URL)
+	bob := joinRole(t, server.URL, session.ID, "bob", "")
+
+	resp, err := http.Post(server.URL+"/api/sessions/"+session.ID+"/observe", "application/json", nil)
+	if err != nil {
+		t.Fatalf("failed to observe session: %v", err)
+	}
+	defer resp.Body.Close()
+	var observed map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&observed); err != nil {
+		t.Fatalf("failed to decode observe response: %v", err)
+	}
+	if observed["role"] != "observer" || observed["token"] == "" {
+		t.Fatalf("expected observer token, got %v", observed)
+	}
+
+	observerConn := dialWebsocket(t, server.URL, session.ID, observed["token"])
+	defer observerConn.Close()
+	expectJoined(t, observerConn, session.ID, qubit.RoleObserver)
+
+	advanceSession(t, server.URL, session.ID, bob)
+	update := waitForStep(t, observerConn, 1)
+	if update.Local.State != "" || update.Local.Measurement != nil {
+		t.Fatalf("expected observer to get no local view, got %+v", update.Local)
+	}
+
+	sendCommand(t, observerConn, map[string]string{"id": "o1", "type": "advance"})
+	if rejected := waitForType(t, observerConn, "error"); rejected.RequestID != "o1" || rejected.Error != "observers are read-only" {
+		t.Fatalf("expected read-only error, got %+v", rejected)
+	}
+}
+
+func TestReplayStreamsRecordedRun(t *testing.T) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	application := app.New(logger)
+
+	server := httptest.NewServer(transporthttp.Middleware(application.Routes(), logger))
+	defer server.Close()
+
+	svc := application.Service
+	session, _ := svc.CreateSession()
+	alice, _ := svc.JoinSession(session.ID, qubit.RoleAlice, "")
+	bob, _ := svc.JoinSession(session.ID, qubit.RoleBob, "")
+	for _, token := range []string{alice.Token, alice.Token, alice.Token, bob.Token} {
+		if _, err := svc.AdvanceStep(session.ID, token); err != nil {
+			t.Fatalf("expected advance to succeed, got %v", err)
+		}
+	}
+	if _, err := svc.ApplyCorrection(session.ID, bob.Token, session.Measurement.Correction); err != nil {
+		t.Fatalf("expected correction to succeed, got %v", err)
+	}
+	if _, err := svc.AdvanceStep(session.ID, bob.Token); err != nil {
+		t.Fatalf("expected advance to succeed, got %v", err)
+	}
+
+	resp, err := http.Get(server.URL + "/api/sessions/" + session.ID + "/replay?token=" + alice.Token)
+	if err != nil {
+		t.Fatalf("failed to request replay: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected replay to require the host token, got %d", resp.StatusCode)
+	}
+
+	conn := dialReplay(t, server.URL, session.ID, session.HostToken, "100x")
+	defer conn.Close()
+	expectJoined(t, conn, session.ID, qubit.RoleInstructor)
+	var steps []int
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for {
+		msg := readMessage(t, conn)
+		if msg.Type == "replay_complete" {
+			break
+		}
+		if msg.Type != "state_update" || msg.Global == nil {
+			t.Fatalf("expected state updates during replay, got %+v", msg)
+		}
+		steps = append(steps, msg.Global.StepIndex)
+	}
+	if len(steps) == 0 || steps[len(steps)-1] != len(session.Steps)-1 {
+		t.Fatalf("expected the replay to reach the final step, got %v", steps)
+	}
+	for i := 1; i < len(steps); i++ {
+		if steps[i] < steps[i-1] {
+			t.Fatalf("expected steps in recorded order, got %v", steps)
+		}
+	}
+
+	stepwise := dialReplay(t, server.URL, session.ID, session.HostToken, "step")
+	defer stepwise.Close()
+	expectJoined(t, stepwise, session.ID, qubit.RoleInstructor)
+	sendCommand(t, stepwise, map[string]string{"id": "n1", "type": "next"})
+	if frame := waitForType(t, stepwise, "state_update"); frame.RequestID != "n1" {
+		t.Fatalf("expected the next frame for n1, got %+v", frame)
+	}
+	sendCommand(t, stepwise, map[string]string{"id": "n2", "type": "advance"})
+	if rejected := waitForType(t, stepwise, "error"); rejected.RequestID != "n2" {
+		t.Fatalf("expected replay to reject live commands, got %+v", rejected)
+	}
+}
+
+func dialReplay(t *testing.T, baseURL, sessionID, token, speed string) *websocket.Conn {
+	t.Helper()
+
+	wsURL, err := url.Parse(baseURL)
+	if err != nil {
+		t.Fatalf("invalid base url: %v", err)
+	}
+	wsURL.Scheme = "ws"
+	wsURL.Path = "/api/sessions/" + sessionID + "/replay"
+	query := wsURL.Query()
+	query.Set("token", token)
+	query.Set("speed", speed)
+	wsURL.RawQuery = query.Encode()
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL.String(), nil)
+	if err != nil {
+		t.Fatalf("failed to connect replay: %v", err)
+	}
+	return conn
+}
@@ -0,0 +1,295 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"sort"
+	"strconv"
+
+	"quantum-teleport/internal/domain/quantum"
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// bb84Protocol distributes a secret key: Alice encodes random bits in random
+// bases, Bob measures in random bases, and the bits where the bases agreed
+// form the key once a disclosed sample shows a low enough error rate. An
+// eavesdropper measuring and resending the qubits disturbs about a quarter of
+// the sifted bits, which the sample reveals.
+//
+// BB84 keeps no joint state: every qubit is measured on its own, so the
+// session register is a single scratch qubit each transmission is simulated on.
+type bb84Protocol struct{}
+
+func (bb84Protocol) Name() string {
+	return teleportation.ProtocolBB84
+}
+
+func (bb84Protocol) Validate(config teleportation.Config) error {
+	if config.Qubits < 0 || config.Qubits > teleportation.MaxBB84Qubits {
+		return errors.New("invalid qubit count")
+	}
+	if err := rejectUnusedOptions(config, optionQubits|optionEavesdropper); err != nil {
+		return err
+	}
+	return rejectInitialState(config)
+}
+
+// bb84Size returns the number of qubits a session sends.
+func bb84Size(config teleportation.Config) int {
+	if config.Qubits == 0 {
+		return teleportation.DefaultBB84Qubits
+	}
+	return config.Qubits
+}
+
+func (bb84Protocol) Steps(config teleportation.Config) []teleportation.StepInfo {
+	steps := []teleportation.StepInfo{
+		{Key: teleportation.StepPrepare, Title: "Подготовка кубитов", Description: "Алиса выбирает случайные биты и базисы и готовит по кубиту на каждый бит."},
+	}
+	if config.Eavesdropper {
+		steps = append(steps, teleportation.StepInfo{Key: teleportation.StepIntercept, Title: "Перехват Евы", Description: "Ева измеряет каждый кубит в случайном базисе и отправляет Бобу то, что получила."})
+	}
+	return append(steps,
+		teleportation.StepInfo{Key: teleportation.StepMeasure, Title: "Измерение Боба", Description: "Боб измеряет каждый кубит в случайно выбранном базисе."},
+		teleportation.StepInfo{Key: teleportation.StepSift, Title: "Согласование базисов", Description: "Алиса и Боб открыто сравнивают базисы и оставляют позиции, где они совпали."},
+		teleportation.StepInfo{Key: teleportation.StepEstimate, Title: "Оценка ошибок", Description: "Половина согласованных битов раскрывается; доля расхождений (QBER) выдаёт шум или перехват."},
+		teleportation.StepInfo{Key: teleportation.StepComplete, Title: "Готово", Description: "Оставшиеся биты образуют общий секретный ключ, если доля ошибок ниже порога."},
+	)
+}
+
+func (bb84Protocol) Roles(config teleportation.Config) []qubit.Role {
+	if config.Eavesdropper {
+		return []qubit.Role{qubit.RoleAlice, qubit.RoleBob, qubit.RoleEve}
+	}
+	return []qubit.Role{qubit.RoleAlice, qubit.RoleBob}
+}
+
+func (bb84Protocol) Allowed(step teleportation.StepInfo, role qubit.Role) bool {
+	switch step.Key {
+	case teleportation.StepPrepare:
+		return role == qubit.RoleAlice
+	case teleportation.StepIntercept:
+		return role == qubit.RoleEve
+	case teleportation.StepMeasure:
+		return role == qubit.RoleBob
+	case teleportation.StepSift, teleportation.StepEstimate:
+		return role == qubit.RoleAlice || role == qubit.RoleBob
+	default:
+		return false
+	}
+}
+
+// Prepare draws Alice's bits and bases and shows one qubit per bit.
+func (bb84Protocol) Prepare(session *teleportation.SessionState) {
+	n := bb84Size(session.Config)
+	bits, bases := randomBits(session, n), randomBases(session, n)
+	session.HiddenState = qubit.BlochState{}
+	session.Register = blankRegister(session.Config, 1)
+	session.KeyExchange = &teleportation.KeyExchange{
+		AliceBits:  bits,
+		AliceBases: bases,
+		KeyReport:  teleportation.KeyReport{Sent: n},
+	}
+	session.Qubits = make([]qubit.Qubit, n)
+	for i := range session.Qubits {
+		session.Qubits[i] = qubit.Qubit{
+			ID:    "q" + strconv.Itoa(i+1),
+			Role:  qubit.RoleAlice,
+			State: "Подготовлен",
+			Bloch: basisState(bits[i], bases[i]),
+		}
+	}
+}
+
+// Ready holds a session with an eavesdropper at the start until Eve has
+// joined: only she moves the intercept step on, so without her the run would
+// stall halfway.
+func (bb84Protocol) Ready(session *teleportation.SessionState) error {
+	if session.CurrentStep().Key == teleportation.StepPrepare && session.Config.Eavesdropper && !session.Participants[qubit.RoleEve].Taken {
+		return errors.New("eve has not joined")
+	}
+	return nil
+}
+
+func (bb84Protocol) Enter(session *teleportation.SessionState) {
+	k := session.KeyExchange
+	switch session.CurrentStep().Key {
+	case teleportation.StepIntercept:
+		k.EveBases = randomBases(session, k.Sent)
+		k.EveBits = measureQubits(session, k.AliceBits, k.AliceBases, k.EveBases, qubit.RoleEve, "Перехвачен и переотправлен")
+		recordKey(session, teleportation.EventQubitsIntercepted, qubit.RoleEve)
+	case teleportation.StepMeasure:
+		// Bob receives whatever was last sent: Eve's resent qubits if she intercepted.
+		bits, bases := k.AliceBits, k.AliceBases
+		if k.EveBases != "" {
+			bits, bases = k.EveBits, k.EveBases
+		}
+		k.BobBases = randomBases(session, k.Sent)
+		k.BobBits = measureQubits(session, bits, bases, k.BobBases, qubit.RoleBob, "Измерен")
+		recordKey(session, teleportation.EventQubitsMeasured, qubit.RoleBob)
+	case teleportation.StepSift:
+		k.Sifted = nil
+		for i := 0; i < k.Sent; i++ {
+			if k.AliceBases[i] == k.BobBases[i] {
+				k.Sifted = append(k.Sifted, i)
+			}
+		}
+		k.KeyReport.Sifted = len(k.Sifted)
+		recordKey(session, teleportation.EventBasesCompared, "")
+	case teleportation.StepEstimate:
+		estimateKey(session)
+		recordKey(session, teleportation.EventKeyEstimated, "")
+	}
+}
+
+// Sync has nothing to refresh: the displayed qubits are set as they change hands.
+func (bb84Protocol) Sync(*teleportation.SessionState) {}
+
+// MeasurementVisible is always false: BB84 has no Bell measurement, its bits
+// live in the key exchange record.
+func (bb84Protocol) MeasurementVisible(*teleportation.SessionState, qubit.Role) bool {
+	return false
+}
+
+// Replay checks the recorded outcome of a step against the one the seed
+// reproduced on entering it.
+func (bb84Protocol) Replay(session *teleportation.SessionState, e teleportation.Event) (bool, error) {
+	switch e.Type {
+	case teleportation.EventQubitsIntercepted, teleportation.EventQubitsMeasured,
+		teleportation.EventBasesCompared, teleportation.EventKeyEstimated:
+		if session.KeyExchange == nil || e.Payload.Key == nil || *e.Payload.Key != session.KeyExchange.KeyReport {
+			return true, errors.New("key exchange does not match the seed")
+		}
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// measureQubits sends the qubits encoded by bits and bases through the channel
+// to role, who measures them in the bases measured, and returns the outcomes.
+// Each qubit is prepared on the scratch register, exposed to the session noise
+// and measured there.
+func measureQubits(session *teleportation.SessionState, bits, bases, measured string, role qubit.Role, state string) string {
+	register := session.Register
+	outcomes := make([]byte, len(bits))
+	for i := range outcomes {
+		register.Reset(0)
+		if bits[i] == '1' {
+			register.Apply(quantum.X, 0)
+		}
+		if bases[i] == teleportation.BasisDiagonal {
+			register.Apply(quantum.H, 0)
+		}
+		applyNoiseLocked(session)
+		if measured[i] == teleportation.BasisDiagonal {
+			register.Apply(quantum.H, 0)
+		}
+		outcomes[i] = byte('0' + register.Measure(0, session.Random.Float64()))
+		session.Qubits[i].Role = role
+		session.Qubits[i].State = state
+		session.Qubits[i].Bloch = basisState(outcomes[i], measured[i])
+	}
+	return string(outcomes)
+}
+
+// estimateKey discloses a random half of the sifted bits, counts where Alice
+// and Bob disagree and keeps the rest as the key unless the error rate is
+// above BB84AbortQBER.
+func estimateKey(session *teleportation.SessionState) {
+	k := session.KeyExchange
+	order := append([]int(nil), k.Sifted...)
+	for i := len(order) - 1; i > 0; i-- {
+		j := int(session.Random.Float64() * float64(i+1))
+		order[i], order[j] = order[j], order[i]
+	}
+	k.Sample = order[:len(order)/2]
+	sort.Ints(k.Sample)
+
+	k.Errors = 0
+	for _, i := range k.Sample {
+		if k.AliceBits[i] != k.BobBits[i] {
+			k.Errors++
+		}
+	}
+	k.QBER = 0
+	if len(k.Sample) > 0 {
+		k.QBER = float64(k.Errors) / float64(len(k.Sample))
+	}
+	k.Aborted = k.QBER > teleportation.BB84AbortQBER
+	k.KeyLength = 0
+	if !k.Aborted {
+		k.KeyLength = len(k.Sifted) - len(k.Sample)
+	}
+}
+
+// recordKey records a BB84 event with the public outcome reached so far.
+func recordKey(session *teleportation.SessionState, typ teleportation.EventType, actor qubit.Role) {
+	report := session.KeyExchange.KeyReport
+	session.Record(teleportation.Event{
+		Type:    typ,
+		Actor:   actor,
+		Payload: teleportation.EventPayload{Step: session.StepIndex, Key: &report},
+	})
+}
+
+// keyExchangeView limits the key exchange to what role may know: its own bits
+// and bases, the bases once they were compared in public and the announced
+// outcome. Eve's choices stay hers.
+func keyExchangeView(session *teleportation.SessionState, role qubit.Role) *teleportation.KeyExchange {
+	k := session.KeyExchange
+	if k == nil {
+		return nil
+	}
+	view := &teleportation.KeyExchange{Sifted: k.Sifted, Sample: k.Sample, KeyReport: k.KeyReport}
+	switch role {
+	case qubit.RoleAlice:
+		view.AliceBits, view.AliceBases = k.AliceBits, k.AliceBases
+	case qubit.RoleBob:
+		view.BobBits, view.BobBases = k.BobBits, k.BobBases
+	case qubit.RoleEve:
+		view.EveBits, view.EveBases = k.EveBits, k.EveBases
+	}
+	if stepReached(session, teleportation.StepSift) {
+		view.AliceBases, view.BobBases = k.AliceBases, k.BobBases
+	}
+	return view
+}
+
+func randomBits(session *teleportation.SessionState, n int) string {
+	bits := make([]byte, n)
+	for i := range bits {
+		bits[i] = '0'
+		if session.Random.Float64() < 0.5 {
+			bits[i] = '1'
+		}
+	}
+	return string(bits)
+}
+
+func randomBases(session *teleportation.SessionState, n int) string {
+	bases := make([]byte, n)
+	for i := range bases {
+		bases[i] = teleportation.BasisRectilinear
+		if session.Random.Float64() < 0.5 {
+			bases[i] = teleportation.BasisDiagonal
+		}
+	}
+	return string(bases)
+}
+
+// basisState returns the Bloch vector of bit encoded in basis: the poles for
+// the rectilinear basis, |+> and |-> on the equator for the diagonal one.
+func basisState(bit, basis byte) qubit.BlochState {
+	if basis == teleportation.BasisDiagonal {
+		if bit == '1' {
+			return qubit.BlochState{Theta: math.Pi / 2, Phi: math.Pi, Radius: 1}
+		}
+		return qubit.BlochState{Theta: math.Pi / 2, Radius: 1}
+	}
+	if bit == '1' {
+		return qubit.BlochState{Theta: math.Pi, Radius: 1}
+	}
+	return qubit.BlochState{Radius: 1}
+}
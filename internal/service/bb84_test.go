@@ -0,0 +1,163 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"quantum-teleport/internal/domain/quantum"
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// runBB84 joins every role of a BB84 session and advances it to completion.
+func runBB84(t *testing.T, service *TeleportationService, session *teleportation.SessionState) {
+	t.Helper()
+	tokens := make(map[qubit.Role]string)
+	for role := range session.Participants {
+		p, _ := service.JoinSession(session.ID, role, "")
+		tokens[role] = p.Token
+	}
+	actors := map[teleportation.Step]qubit.Role{
+		teleportation.StepPrepare:   qubit.RoleAlice,
+		teleportation.StepIntercept: qubit.RoleEve,
+		teleportation.StepMeasure:   qubit.RoleBob,
+		teleportation.StepSift:      qubit.RoleAlice,
+		teleportation.StepEstimate:  qubit.RoleBob,
+	}
+	for session.CurrentStep().Key != teleportation.StepComplete {
+		step := session.CurrentStep()
+		if _, err := service.AdvanceStep(session.ID, tokens[actors[step.Key]]); err != nil {
+			t.Fatalf("expected %s to advance from %q, got %v", actors[step.Key], step.Title, err)
+		}
+	}
+}
+
+func TestBB84SharesAKeyWithoutEavesdropper(t *testing.T) {
+	for seed := int64(1); seed <= 6; seed++ {
+		service := NewTeleportationService(WithSeed(seed))
+		session, err := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolBB84, Qubits: 32})
+		if err != nil {
+			t.Fatalf("expected a BB84 session, got %v", err)
+		}
+		if _, ok := session.Participants[qubit.RoleEve]; ok || len(session.Qubits) != 32 {
+			t.Fatalf("expected 32 qubits and no eve, got %d qubits and %v", len(session.Qubits), session.Participants)
+		}
+		runBB84(t, service, session)
+
+		k := session.KeyExchange
+		for _, i := range k.Sifted {
+			if k.AliceBits[i] != k.BobBits[i] {
+				t.Fatalf("expected matching bases to give matching bits at %d, got %+v", i, k)
+			}
+		}
+		if k.Sifted == nil || k.Errors != 0 || k.QBER != 0 || k.Aborted || k.KeyLength != len(k.Sifted)-len(k.Sample) {
+			t.Fatalf("expected an error-free key, got %+v", k.KeyReport)
+		}
+
+		rebuilt, err := service.Rebuild(session.Events)
+		if err != nil || rebuilt.KeyExchange.KeyReport != k.KeyReport || rebuilt.KeyExchange.BobBits != k.BobBits {
+			t.Fatalf("expected the exchange to replay, got %+v, %v", rebuilt, err)
+		}
+	}
+}
+
+func TestBB84DetectsInterceptResend(t *testing.T) {
+	service := NewTeleportationService(WithSeed(11))
+	session, err := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolBB84, Qubits: 64, Eavesdropper: true})
+	if err != nil {
+		t.Fatalf("expected a BB84 session with eve, got %v", err)
+	}
+	runBB84(t, service, session)
+
+	k := session.KeyExchange
+	if k.EveBases == "" || k.Errors == 0 || k.QBER <= teleportation.BB84AbortQBER || !k.Aborted || k.KeyLength != 0 {
+		t.Fatalf("expected eve to push the error rate over the threshold, got %+v", k.KeyReport)
+	}
+	if line := session.Log[len(session.Log)-2]; !strings.HasSuffix(line, "выше порога, ключ отброшен") {
+		t.Fatalf("expected the aborted key in the log, got %q", line)
+	}
+}
+
+func TestBB84WaitsForEveBeforeStarting(t *testing.T) {
+	service := NewTeleportationService()
+	session, _ := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolBB84, Eavesdropper: true})
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+	if _, err := service.AdvanceStep(session.ID, alice.Token); err == nil || err.Error() != "eve has not joined" {
+		t.Fatalf("expected the run to wait for eve, got %v", err)
+	}
+	if _, err := service.JoinSession(session.ID, qubit.RoleEve, ""); err != nil {
+		t.Fatalf("expected eve to join, got %v", err)
+	}
+	if _, err := service.AdvanceStep(session.ID, alice.Token); err != nil || session.CurrentStep().Key != teleportation.StepIntercept {
+		t.Fatalf("expected the qubits to reach eve once she joined, got %v", err)
+	}
+}
+
+func TestBB84NoiseRaisesTheErrorRate(t *testing.T) {
+	service := NewTeleportationService(WithSeed(5))
+	session, _ := service.CreateSessionWithConfig(teleportation.Config{
+		Protocol: teleportation.ProtocolBB84,
+		Qubits:   64,
+		Noise:    &teleportation.NoiseProfile{Channel: quantum.ChannelDepolarizing, Probability: 0.3},
+	})
+	runBB84(t, service, session)
+	if session.KeyExchange.Errors == 0 {
+		t.Fatalf("expected noise to cause errors, got %+v", session.KeyExchange.KeyReport)
+	}
+}
+
+func TestBB84KeepsBasesPrivateUntilSifting(t *testing.T) {
+	service := NewTeleportationService(WithSeed(2))
+	if _, err := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolBB84, Qubits: teleportation.MaxBB84Qubits + 1}); err == nil || err.Error() != "invalid qubit count" {
+		t.Fatalf("expected too many qubits to be refused, got %v", err)
+	}
+	session, _ := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolBB84, Eavesdropper: true})
+	if len(session.Qubits) != teleportation.DefaultBB84Qubits {
+		t.Fatalf("expected the default size, got %d qubits", len(session.Qubits))
+	}
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+	bob, _ := service.JoinSession(session.ID, qubit.RoleBob, "")
+	eve, err := service.JoinSession(session.ID, qubit.RoleEve, "")
+	if err != nil {
+		t.Fatalf("expected eve to join, got %v", err)
+	}
+
+	if _, err := service.AdvanceStep(session.ID, eve.Token); err == nil {
+		t.Fatal("expected only alice to send the qubits")
+	}
+	_, _ = service.AdvanceStep(session.ID, alice.Token)
+	if _, err := service.AdvanceStep(session.ID, bob.Token); err == nil {
+		t.Fatal("expected eve to hold the qubits first")
+	}
+	eveView, _ := service.SessionView(session.ID, eve.Token)
+	if eveView.KeyExchange.AliceBases != "" || eveView.KeyExchange.EveBits == "" || eveView.Qubits[0].Hidden {
+		t.Fatalf("expected eve to see only her own measurements, got %+v", eveView.KeyExchange)
+	}
+	_, _ = service.AdvanceStep(session.ID, eve.Token)
+
+	bobView, _ := service.SessionView(session.ID, bob.Token)
+	if bobView.KeyExchange.AliceBases != "" || bobView.KeyExchange.EveBits != "" || bobView.KeyExchange.BobBits == "" {
+		t.Fatalf("expected bob to see only his own bases and bits, got %+v", bobView.KeyExchange)
+	}
+	_, _ = service.AdvanceStep(session.ID, bob.Token)
+
+	observer, _ := service.SessionView(session.ID, "")
+	if observer.KeyExchange.AliceBases == "" || observer.KeyExchange.AliceBits != "" || observer.KeyExchange.BobBits != "" || observer.KeyExchange.KeyReport.Sifted != len(session.KeyExchange.Sifted) {
+		t.Fatalf("expected the bases but not the bits to be public after sifting, got %+v", observer.KeyExchange)
+	}
+}
+
+func TestBB84HostViewIsACopy(t *testing.T) {
+	service := NewTeleportationService(WithSeed(3))
+	session, _ := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolBB84, Qubits: 16})
+	view, err := service.SessionView(session.ID, session.HostToken)
+	if err != nil || view.KeyExchange == nil || view.KeyExchange == session.KeyExchange {
+		t.Fatalf("expected the host view to hold its own key exchange, got %+v, %v", view.KeyExchange, err)
+	}
+	before := *view.KeyExchange.Clone()
+
+	runBB84(t, service, session)
+	if view.KeyExchange.BobBits != before.BobBits || len(view.KeyExchange.Sifted) != len(before.Sifted) || session.KeyExchange.BobBits == before.BobBits {
+		t.Fatalf("expected the run not to reach into an earlier host view, got %+v", view.KeyExchange)
+	}
+}
@@ -0,0 +1,240 @@
+package service
+
+import (
+	"errors"
+	"strconv"
+
+	"quantum-teleport/internal/domain/quantum"
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// chainProtocol teleports the unknown state hop by hop from Alice through
+// Config.Relays relay nodes to Bob. Every hop is a full teleportation between
+// neighbours on the same three-qubit register: the state arrives on
+// registerBob, moves back to registerUnknown for the next hop and the two
+// measured qubits are reset into a fresh pair.
+type chainProtocol struct{}
+
+func (chainProtocol) Name() string {
+	return teleportation.ProtocolChain
+}
+
+func (chainProtocol) Validate(config teleportation.Config) error {
+	if config.Relays < 1 || config.Relays > teleportation.MaxChainRelays {
+		return errors.New("invalid chain length")
+	}
+	return rejectUnusedOptions(config, optionRelays)
+}
+
+func (chainProtocol) Steps(config teleportation.Config) []teleportation.StepInfo {
+	hops := config.Relays + 1
+	steps := []teleportation.StepInfo{{
+		Key:         teleportation.StepEntangle,
+		Title:       "Подготовка цепочки",
+		Description: "Состояние Алисы пройдёт к Бобу через " + strconv.Itoa(config.Relays) + " промежуточных узлов, по одной телепортации на хоп.",
+		Hop:         chainHop(config, 1),
+	}}
+	for h := 1; h <= hops; h++ {
+		hop := chainHop(config, h)
+		prefix := "Хоп " + strconv.Itoa(h) + " (" + nodeName(hop.From) + " → " + nodeName(hop.To) + "): "
+		steps = append(steps,
+			teleportation.StepInfo{Key: teleportation.StepCombine, Title: prefix + "пара и объединение", Description: "Соседние узлы делят новую пару Белла, отправитель связывает с ней переносимое состояние.", Hop: hop},
+			teleportation.StepInfo{Key: teleportation.StepMeasure, Title: prefix + "измерение", Description: "Отправитель делает измерение Белла, разрушая свою копию состояния.", Hop: hop},
+			teleportation.StepInfo{Key: teleportation.StepSend, Title: prefix + "передача битов", Description: "Два классических бита уходят получателю хопа.", Hop: hop},
+			teleportation.StepInfo{Key: teleportation.StepReconstruct, Title: prefix + "коррекция", Description: "Получатель применяет коррекцию Паули и становится отправителем следующего хопа.", Hop: hop},
+		)
+	}
+	return append(steps, teleportation.StepInfo{
+		Key:         teleportation.StepComplete,
+		Title:       "Готово",
+		Description: "Состояние прошло всю цепочку; точность по хопам показывает, как накапливаются ошибки.",
+	})
+}
+
+// chainHop describes hop h of a chain session, from node h-1 to node h.
+func chainHop(config teleportation.Config, h int) *teleportation.Hop {
+	return &teleportation.Hop{
+		Index: h,
+		From:  teleportation.ChainNode(h-1, config.Relays),
+		To:    teleportation.ChainNode(h, config.Relays),
+	}
+}
+
+// nodeName renders a chain node for step titles.
+func nodeName(role qubit.Role) string {
+	switch role {
+	case qubit.RoleAlice:
+		return "Алиса"
+	case qubit.RoleBob:
+		return "Боб"
+	}
+	i, _ := qubit.RelayIndex(role)
+	return "узел " + strconv.Itoa(i)
+}
+
+func (chainProtocol) Roles(config teleportation.Config) []qubit.Role {
+	roles := make([]qubit.Role, 0, config.Relays+2)
+	for i := 0; i <= config.Relays+1; i++ {
+		roles = append(roles, teleportation.ChainNode(i, config.Relays))
+	}
+	return roles
+}
+
+// Allowed lets either end of the first hop share the first pair, the sender of
+// a hop combine and measure and its receiver take the bits and correct.
+func (chainProtocol) Allowed(step teleportation.StepInfo, role qubit.Role) bool {
+	hop := step.Hop
+	if hop == nil {
+		return false
+	}
+	switch step.Key {
+	case teleportation.StepEntangle:
+		return role == hop.From || role == hop.To
+	case teleportation.StepCombine, teleportation.StepMeasure:
+		return role == hop.From
+	case teleportation.StepSend, teleportation.StepReconstruct:
+		return role == hop.To
+	default:
+		return false
+	}
+}
+
+// Prepare draws the unknown state like teleportation and shows one qubit per node.
+func (chainProtocol) Prepare(session *teleportation.SessionState) {
+	if session.Config.InitialState != nil {
+		session.HiddenState = *session.Config.InitialState
+	} else {
+		session.HiddenState = randomBlochState(session.Random)
+	}
+	session.Register = newRegister(session.Config, session.HiddenState)
+	nodes := session.Config.Relays + 2
+	session.Qubits = make([]qubit.Qubit, nodes)
+	for i := range session.Qubits {
+		session.Qubits[i] = qubit.Qubit{
+			ID:    "q" + strconv.Itoa(i+1),
+			Role:  teleportation.ChainNode(i, session.Config.Relays),
+			State: "Чистое состояние",
+		}
+	}
+	session.Qubits[0].State = "Неизвестное состояние"
+}
+
+func (chainProtocol) Ready(session *teleportation.SessionState) error {
+	if session.CurrentStep().Key == teleportation.StepReconstruct && session.Correction == nil {
+		return errors.New("correction not applied")
+	}
+	return nil
+}
+
+func (chainProtocol) Enter(session *teleportation.SessionState) {
+	register := session.Register
+	step := session.CurrentStep()
+	switch step.Key {
+	case teleportation.StepCombine:
+		hop := step.Hop
+		if hop.Index > 1 {
+			archiveHop(session)
+			// The state the sender received sits on registerBob: swap it onto
+			// registerUnknown and recycle the two measured qubits into a new pair.
+			register.CNOT(registerUnknown, registerBob)
+			register.CNOT(registerBob, registerUnknown)
+			register.CNOT(registerUnknown, registerBob)
+			register.Reset(registerAliceHalf)
+			register.Reset(registerBob)
+			session.Measurement = nil
+			session.Correction = nil
+		}
+		register.Apply(quantum.H, registerAliceHalf)
+		register.CNOT(registerAliceHalf, registerBob)
+		register.CNOT(registerUnknown, registerAliceHalf)
+		session.Qubits[hop.Index-1].State = "Связан с парой"
+		session.Qubits[hop.Index].State = "Запутанная пара готова"
+	case teleportation.StepMeasure:
+		register.Apply(quantum.H, registerUnknown)
+		m1, m2, p := register.MeasurePair(registerUnknown, registerAliceHalf, session.Random.Float64())
+		measurement := teleportation.NewMeasurement(m1, m2, p)
+		session.Measurement = &measurement
+		session.Qubits[step.Hop.Index-1].State = "Измерен"
+		session.Record(teleportation.Event{
+			Type:    teleportation.EventMeasurementTaken,
+			Actor:   step.Hop.From,
+			Payload: teleportation.EventPayload{Step: session.StepIndex, Measurement: &measurement},
+		})
+	case teleportation.StepSend:
+		bits := *session.Measurement
+		session.Record(teleportation.Event{
+			Type:    teleportation.EventBitsSent,
+			Actor:   step.Hop.From,
+			Payload: teleportation.EventPayload{Step: session.StepIndex, Role: step.Hop.To, Measurement: &bits},
+		})
+	case teleportation.StepReconstruct:
+		session.Qubits[step.Hop.Index].State = "Ожидает коррекцию"
+	case teleportation.StepComplete:
+		archiveHop(session)
+		restored := true
+		for _, h := range session.Hops {
+			restored = restored && h.Correction.Correct
+		}
+		last := len(session.Qubits) - 1
+		if restored {
+			session.Qubits[last].State = "Состояние восстановлено"
+		} else {
+			session.Qubits[last].State = "Состояние искажено"
+		}
+	}
+}
+
+// archiveHop records the hop the session has just finished with its bits and
+// correction. It runs on entering the step after that hop's reconstruct.
+func archiveHop(session *teleportation.SessionState) {
+	hop := session.Steps[session.StepIndex-1].Hop
+	session.Hops = append(session.Hops, teleportation.HopResult{
+		Hop:         *hop,
+		Measurement: *session.Measurement,
+		Correction:  *session.Correction,
+	})
+}
+
+// Sync shows the register on the two nodes of the latest hop: the sender's
+// qubit is registerUnknown and the receiver's registerBob. Nodes the state has
+// left keep the vector they were last shown with.
+func (chainProtocol) Sync(session *teleportation.SessionState) {
+	var hop *teleportation.Hop
+	for i := 0; i <= session.StepIndex && i < len(session.Steps); i++ {
+		if h := session.Steps[i].Hop; h != nil {
+			hop = h
+		}
+	}
+	if hop == nil {
+		return
+	}
+	session.Qubits[hop.Index-1].Bloch = session.Register.Bloch(registerUnknown)
+	session.Qubits[hop.Index].Bloch = session.Register.Bloch(registerBob)
+}
+
+// MeasurementVisible gives the sender of a hop its bits right after measuring
+// and everyone else once they were sent.
+func (chainProtocol) MeasurementVisible(session *teleportation.SessionState, role qubit.Role) bool {
+	step := session.CurrentStep()
+	switch step.Key {
+	case teleportation.StepMeasure:
+		return role == step.Hop.From
+	case teleportation.StepSend, teleportation.StepReconstruct, teleportation.StepComplete:
+		return true
+	default:
+		return false
+	}
+}
+
+// Correct applies the receiver's Pauli correction and scores the forwarded
+// state against the original unknown state, so the fidelity of later hops
+// includes the errors picked up on earlier ones.
+func (p chainProtocol) Correct(session *teleportation.SessionState, correction teleportation.Correction) teleportation.CorrectionAttempt {
+	attempt := correctPauliLocked(session, registerBob, correction, session.Measurement.Correction, func() float64 {
+		return quantum.Fidelity(session.Register.Reduced(registerBob), session.HiddenState)
+	})
+	session.Qubits[session.CurrentStep().Hop.Index].State = "Коррекция применена: " + string(correction)
+	p.Sync(session)
+	return attempt
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"quantum-teleport/internal/domain/quantum"
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// runChain joins every node of a chain session and drives it to completion,
+// each receiver applying the correction its bits dictate.
+func runChain(t *testing.T, service *TeleportationService, session *teleportation.SessionState) {
+	t.Helper()
+	tokens := make(map[qubit.Role]string)
+	for role := range session.Participants {
+		p, err := service.JoinSession(session.ID, role, "")
+		if err != nil {
+			t.Fatalf("expected %s to join, got %v", role, err)
+		}
+		tokens[role] = p.Token
+	}
+	for session.CurrentStep().Key != teleportation.StepComplete {
+		step := session.CurrentStep()
+		actor := step.Hop.From
+		if step.Key == teleportation.StepSend || step.Key == teleportation.StepReconstruct {
+			actor = step.Hop.To
+		}
+		if step.Key == teleportation.StepReconstruct {
+			if _, err := service.ApplyCorrection(session.ID, tokens[actor], session.Measurement.Correction); err != nil {
+				t.Fatalf("expected %s to correct on %q, got %v", actor, step.Title, err)
+			}
+		}
+		if _, err := service.AdvanceStep(session.ID, tokens[actor]); err != nil {
+			t.Fatalf("expected %s to advance from %q, got %v", actor, step.Title, err)
+		}
+	}
+}
+
+func TestChainTeleportsHopByHop(t *testing.T) {
+	for seed := int64(1); seed <= 6; seed++ {
+		service := NewTeleportationService(WithSeed(seed))
+		session, err := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolChain, Relays: 2})
+		if err != nil {
+			t.Fatalf("expected a chain session, got %v", err)
+		}
+		if len(session.Participants) != 4 || len(session.Qubits) != 4 || len(session.Steps) != 14 {
+			t.Fatalf("expected four nodes and three hops, got %d roles, %d qubits, %d steps", len(session.Participants), len(session.Qubits), len(session.Steps))
+		}
+		if session.Qubits[1].Role != qubit.RelayRole(1) || session.Qubits[3].Role != qubit.RoleBob {
+			t.Fatalf("expected alice, relay1, relay2, bob in order, got %+v", session.Qubits)
+		}
+
+		runChain(t, service, session)
+
+		if len(session.Hops) != 3 {
+			t.Fatalf("expected three archived hops, got %+v", session.Hops)
+		}
+		for i, hop := range session.Hops {
+			if hop.Index != i+1 || !hop.Correction.Correct || math.Abs(hop.Correction.Fidelity-1) > 1e-9 {
+				t.Fatalf("expected hop %d to deliver the state perfectly, got %+v", i+1, hop)
+			}
+		}
+		if session.Hops[1].From != qubit.RelayRole(1) || session.Hops[1].To != qubit.RelayRole(2) {
+			t.Fatalf("expected the middle hop between the relays, got %+v", session.Hops[1])
+		}
+		if got := quantum.Fidelity(session.Register.Reduced(registerBob), session.HiddenState); math.Abs(got-1) > 1e-9 {
+			t.Fatalf("expected bob to hold the unknown state, got fidelity %f", got)
+		}
+		if session.Qubits[3].State != "Состояние восстановлено" {
+			t.Fatalf("expected bob to report the state restored, got %q", session.Qubits[3].State)
+		}
+
+		rebuilt, err := service.Rebuild(session.Events)
+		if err != nil || len(rebuilt.Hops) != 3 || rebuilt.Hops[2] != session.Hops[2] {
+			t.Fatalf("expected the chain to replay, got %+v, %v", rebuilt, err)
+		}
+	}
+}
+
+func TestChainAccumulatesNoiseAcrossHops(t *testing.T) {
+	service := NewTeleportationService(WithSeed(7))
+	one, _ := qubit.Preset("1")
+	session, err := service.CreateSessionWithConfig(teleportation.Config{
+		Protocol:     teleportation.ProtocolChain,
+		Relays:       3,
+		InitialState: &one,
+		Noise:        &teleportation.NoiseProfile{Channel: quantum.ChannelDepolarizing, Probability: 0.02},
+	})
+	if err != nil {
+		t.Fatalf("expected a noisy chain, got %v", err)
+	}
+	runChain(t, service, session)
+
+	for i := 1; i < len(session.Hops); i++ {
+		if session.Hops[i].Correction.Fidelity >= session.Hops[i-1].Correction.Fidelity {
+			t.Fatalf("expected fidelity to drop with every hop, got %+v", session.Hops)
+		}
+	}
+	if session.Hops[len(session.Hops)-1].Correction.Fidelity > 0.95 {
+		t.Fatalf("expected noticeable loss after four hops, got %+v", session.Hops)
+	}
+}
+
+func TestChainLimitsEachStepToItsHop(t *testing.T) {
+	service := NewTeleportationService(WithSeed(3))
+	for _, relays := range []int{0, -1, teleportation.MaxChainRelays + 1} {
+		if _, err := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolChain, Relays: relays}); err == nil || err.Error() != "invalid chain length" {
+			t.Fatalf("expected %d relays to be refused, got %v", relays, err)
+		}
+	}
+
+	session, _ := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolChain, Relays: 1})
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+	relay, err := service.JoinSession(session.ID, qubit.RelayRole(1), "")
+	if err != nil {
+		t.Fatalf("expected relay1 to join, got %v", err)
+	}
+	bob, _ := service.JoinSession(session.ID, qubit.RoleBob, "")
+	if _, err := service.JoinSession(session.ID, qubit.RelayRole(2), ""); err == nil {
+		t.Fatal("expected relay2 not to exist in a one-relay chain")
+	}
+
+	if _, err := service.AdvanceStep(session.ID, bob.Token); err == nil {
+		t.Fatal("expected bob not to act on the first hop")
+	}
+	for _, token := range []string{relay.Token, alice.Token} {
+		if _, err := service.AdvanceStep(session.ID, token); err != nil {
+			t.Fatalf("expected the first hop to proceed, got %v", err)
+		}
+	}
+
+	relayView, _ := service.SessionView(session.ID, relay.Token)
+	if relayView.ActiveHop == nil || relayView.ActiveHop.Index != 1 || relayView.Measurement != nil {
+		t.Fatalf("expected relay1 to see hop 1 active without its bits, got %+v", relayView.ActiveHop)
+	}
+	_, _ = service.AdvanceStep(session.ID, alice.Token)
+	_, _ = service.AdvanceStep(session.ID, relay.Token)
+	relayView, _ = service.SessionView(session.ID, relay.Token)
+	if relayView.Measurement == nil || relayView.Qubits[1].Hidden || !relayView.Qubits[2].Hidden {
+		t.Fatalf("expected relay1 to get the bits and see only its qubit, got %+v", relayView)
+	}
+
+	if _, err := service.ApplyCorrection(session.ID, bob.Token, teleportation.CorrectionX); err == nil {
+		t.Fatal("expected only relay1 to correct on the first hop")
+	}
+	_, _ = service.ApplyCorrection(session.ID, relay.Token, session.Measurement.Correction)
+	_, _ = service.AdvanceStep(session.ID, relay.Token)
+	if _, err := service.AdvanceStep(session.ID, alice.Token); err == nil {
+		t.Fatal("expected alice to be done after the first hop")
+	}
+	if session.CurrentStep().Hop.From != qubit.RelayRole(1) || len(session.Hops) != 1 {
+		t.Fatalf("expected relay1 to send the second hop, got %+v", session.CurrentStep())
+	}
+
+	_, _ = service.AdvanceStep(session.ID, relay.Token)
+	want := []string{"Узел 1 выполнил измерение Белла", "Классические биты отправлены Бобу: " + session.Measurement.Bits()}
+	_, _ = service.AdvanceStep(session.ID, relay.Token)
+	if log := session.Log[len(session.Log)-4:]; log[1] != want[0] || log[3] != want[1] {
+		t.Fatalf("expected the log to name the relay and the receiver, got %v", session.Log)
+	}
+}
@@ -0,0 +1,34 @@
+package service
+
+import (
+	"errors"
+
+	"quantum-teleport/internal/domain/circuit"
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// Circuit returns the gate-level circuit of a session as the holder of token
+// may see it: the prepared angles are filled in for the instructor only. An
+// empty token yields the public circuit. Only teleportation has a circuit model.
+func (s *TeleportationService) Circuit(id string, token string) (circuit.Circuit, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions.Get(id)
+	if !ok {
+		return circuit.Circuit{}, errors.New("session not found")
+	}
+	if session.Config.Protocol != teleportation.ProtocolTeleportation {
+		return circuit.Circuit{}, errors.New("circuit unsupported for protocol")
+	}
+	role := qubit.RoleObserver
+	if token != "" {
+		r, err := s.listenerRoleLocked(session, token)
+		if err != nil {
+			return circuit.Circuit{}, err
+		}
+		role = r
+	}
+	return circuit.FromSession(session, role == qubit.RoleInstructor), nil
+}
@@ -0,0 +1,200 @@
+package service
+
+import (
+	"errors"
+
+	"quantum-teleport/internal/domain/quantum"
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// Register layout of controlled teleportation: Alice's unknown qubit and the
+// three qubits of the GHZ state held by Alice, Bob and Charlie.
+const (
+	controlledUnknown = iota
+	controlledAlice
+	controlledBob
+	controlledCharlie
+)
+
+// controlledProtocol teleports Alice's unknown qubit through a GHZ state
+// instead of a Bell pair. Alice's two bits are not enough: until Charlie
+// measures his qubit in the X basis and announces the result, Bob's qubit
+// stays entangled with Charlie's and no correction restores the state.
+type controlledProtocol struct{}
+
+func (controlledProtocol) Name() string {
+	return teleportation.ProtocolControlled
+}
+
+func (controlledProtocol) Steps(teleportation.Config) []teleportation.StepInfo {
+	return []teleportation.StepInfo{
+		{Key: teleportation.StepEntangle, Title: "Раздача GHZ-состояния", Description: "Алиса, Боб и Чарли получают по кубиту общего состояния (|000⟩+|111⟩)/√2."},
+		{Key: teleportation.StepCombine, Title: "Объединение состояний", Description: "Алиса связывает неизвестный кубит со своей частью GHZ-состояния."},
+		{Key: teleportation.StepMeasure, Title: "Измерение Алисы", Description: "Алиса делает измерение Белла над неизвестным кубитом и своей частью."},
+		{Key: teleportation.StepSend, Title: "Биты Алисы у Боба", Description: "Боб знает биты Алисы, но его кубит ещё запутан с кубитом Чарли: без Чарли состояние не восстановить."},
+		{Key: teleportation.StepControl, Title: "Помощь Чарли", Description: "Чарли измеряет свой кубит в базисе X и сообщает бит Бобу."},
+		{Key: teleportation.StepReconstruct, Title: "Восстановление у Боба", Description: "Боб применяет коррекцию по битам Алисы и Чарли."},
+		{Key: teleportation.StepComplete, Title: "Готово", Description: "Состояние перенесено с согласия Чарли."},
+	}
+}
+
+func (controlledProtocol) Roles(teleportation.Config) []qubit.Role {
+	return []qubit.Role{qubit.RoleAlice, qubit.RoleBob, qubit.RoleCharlie}
+}
+
+// Allowed leaves the decision to cooperate to Charlie: only he moves the
+// session on from the step where Bob holds Alice's bits.
+func (controlledProtocol) Allowed(step teleportation.StepInfo, role qubit.Role) bool {
+	switch step.Key {
+	case teleportation.StepEntangle:
+		return role == qubit.RoleAlice || role == qubit.RoleBob || role == qubit.RoleCharlie
+	case teleportation.StepCombine, teleportation.StepMeasure:
+		return role == qubit.RoleAlice
+	case teleportation.StepSend:
+		return role == qubit.RoleCharlie
+	case teleportation.StepControl, teleportation.StepReconstruct:
+		return role == qubit.RoleBob
+	default:
+		return false
+	}
+}
+
+func (controlledProtocol) Validate(config teleportation.Config) error {
+	return rejectUnusedOptions(config, 0)
+}
+
+// Prepare draws the unknown state like teleportation on a four-qubit register.
+func (controlledProtocol) Prepare(session *teleportation.SessionState) {
+	if session.Config.InitialState != nil {
+		session.HiddenState = *session.Config.InitialState
+	} else {
+		session.HiddenState = randomBlochState(session.Random)
+	}
+	session.Register = blankRegister(session.Config, 4)
+	session.Register.Apply(quantum.Prepare(session.HiddenState), controlledUnknown)
+	session.Qubits = []qubit.Qubit{
+		{ID: "q1", Role: qubit.RoleAlice, State: "Неизвестное состояние"},
+		{ID: "q2", Role: qubit.RoleBob, State: "Чистое состояние"},
+		{ID: "q3", Role: qubit.RoleCharlie, State: "Чистое состояние"},
+	}
+}
+
+func (controlledProtocol) Ready(session *teleportation.SessionState) error {
+	if session.CurrentStep().Key == teleportation.StepReconstruct && session.Correction == nil {
+		return errors.New("correction not applied")
+	}
+	return nil
+}
+
+func (controlledProtocol) Enter(session *teleportation.SessionState) {
+	register := session.Register
+	switch session.CurrentStep().Key {
+	case teleportation.StepCombine:
+		register.Apply(quantum.H, controlledAlice)
+		register.CNOT(controlledAlice, controlledBob)
+		register.CNOT(controlledAlice, controlledCharlie)
+		register.CNOT(controlledUnknown, controlledAlice)
+		session.Qubits[0].State = "Связан с GHZ-состоянием"
+		session.Qubits[1].State = "Часть GHZ-состояния"
+		session.Qubits[2].State = "Часть GHZ-состояния"
+	case teleportation.StepMeasure:
+		register.Apply(quantum.H, controlledUnknown)
+		m1, m2, p := register.MeasurePair(controlledUnknown, controlledAlice, session.Random.Float64())
+		measurement := teleportation.NewMeasurement(m1, m2, p)
+		session.Measurement = &measurement
+		session.Qubits[0].State = "Измерен"
+		session.Record(teleportation.Event{
+			Type:    teleportation.EventMeasurementTaken,
+			Actor:   qubit.RoleAlice,
+			Payload: teleportation.EventPayload{Step: session.StepIndex, Measurement: &measurement},
+		})
+	case teleportation.StepSend:
+		bits := *session.Measurement
+		session.Qubits[1].State = "Ждёт помощи Чарли"
+		session.Record(teleportation.Event{
+			Type:    teleportation.EventBitsSent,
+			Actor:   qubit.RoleAlice,
+			Payload: teleportation.EventPayload{Step: session.StepIndex, Measurement: &bits},
+		})
+	case teleportation.StepControl:
+		// Score Bob's best effort without Charlie before his measurement
+		// disentangles the two qubits.
+		without := probeControlled(session, session.Measurement.Correction)
+		register.Apply(quantum.H, controlledCharlie)
+		p1 := register.Probability(controlledCharlie)
+		bit := register.Measure(controlledCharlie, session.Random.Float64())
+		p := 1 - p1
+		if bit == 1 {
+			p = p1
+		}
+		control := teleportation.Control{
+			Bit:             bit,
+			Probability:     p,
+			FidelityWithout: without,
+			Correction:      teleportation.ControlledCorrection(*session.Measurement, bit),
+		}
+		session.Control = &control
+		session.Qubits[2].State = "Измерен в базисе X"
+		session.Record(teleportation.Event{
+			Type:    teleportation.EventControlMeasured,
+			Actor:   qubit.RoleCharlie,
+			Payload: teleportation.EventPayload{Step: session.StepIndex, Control: &control},
+		})
+	case teleportation.StepReconstruct:
+		session.Qubits[1].State = "Ожидает коррекцию"
+	case teleportation.StepComplete:
+		if session.Correction.Correct {
+			session.Qubits[1].State = "Состояние восстановлено"
+		} else {
+			session.Qubits[1].State = "Состояние искажено"
+		}
+	}
+}
+
+// Replay checks Charlie's recorded bit against the one the seed reproduced.
+func (controlledProtocol) Replay(session *teleportation.SessionState, e teleportation.Event) (bool, error) {
+	if e.Type != teleportation.EventControlMeasured {
+		return false, nil
+	}
+	if session.Control == nil || e.Payload.Control == nil || *e.Payload.Control != *session.Control {
+		return true, errors.New("control does not match the seed")
+	}
+	return true, nil
+}
+
+// probeControlled returns the fidelity Bob would reach with correction, probed on a copy.
+func probeControlled(session *teleportation.SessionState, correction teleportation.Correction) float64 {
+	probe, err := quantum.Capture(session.Register).Restore()
+	if err != nil {
+		return 0
+	}
+	applyPauli(probe, controlledBob, correction)
+	return quantum.Fidelity(probe.Reduced(controlledBob), session.HiddenState)
+}
+
+func (controlledProtocol) Sync(session *teleportation.SessionState) {
+	session.Qubits[0].Bloch = session.Register.Bloch(controlledUnknown)
+	session.Qubits[1].Bloch = session.Register.Bloch(controlledBob)
+	session.Qubits[2].Bloch = session.Register.Bloch(controlledCharlie)
+}
+
+// MeasurementVisible gives Alice her bits right after measuring and everyone
+// else once they were sent.
+func (controlledProtocol) MeasurementVisible(session *teleportation.SessionState, role qubit.Role) bool {
+	if role == qubit.RoleAlice {
+		return stepReached(session, teleportation.StepMeasure)
+	}
+	return stepReached(session, teleportation.StepSend)
+}
+
+// Correct applies Bob's Pauli correction; it is right when it accounts for
+// Charlie's bit as well as Alice's.
+func (p controlledProtocol) Correct(session *teleportation.SessionState, correction teleportation.Correction) teleportation.CorrectionAttempt {
+	attempt := correctPauliLocked(session, controlledBob, correction, session.Control.Correction, func() float64 {
+		return quantum.Fidelity(session.Register.Reduced(controlledBob), session.HiddenState)
+	})
+	session.Qubits[1].State = "Коррекция применена: " + string(correction)
+	p.Sync(session)
+	return attempt
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"errors"
+
+	"quantum-teleport/internal/domain/quantum"
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// interceptLocked puts Alice's bits in Eve's hands with the bits marked in
+// flip inverted, and scores what following the delivered bits would cost Bob:
+// the fidelity after the correction they dictate against the one Alice's bits
+// dictate, both probed on a copy of the register.
+func interceptLocked(session *teleportation.SessionState, flip string) teleportation.Interception {
+	interception, _ := teleportation.NewInterception(*session.Measurement, flip)
+	honest := probeCorrection(session, session.Measurement.Correction)
+	delivered := probeCorrection(session, interception.Delivered.Correction)
+	interception.FidelityDrop = honest - delivered
+	session.Interception = &interception
+	return interception
+}
+
+// probeCorrection returns the fidelity Bob would reach with correction.
+func probeCorrection(session *teleportation.SessionState, correction teleportation.Correction) float64 {
+	probe, err := quantum.Capture(session.Register).Restore()
+	if err != nil {
+		return 0
+	}
+	applyPauli(probe, registerBob, correction)
+	return quantum.Fidelity(probe.Reduced(registerBob), session.HiddenState)
+}
+
+// deliveredBits returns the bits that reach Bob: Alice's, or Eve's version
+// when she intercepted them.
+func deliveredBits(session *teleportation.SessionState) teleportation.Measurement {
+	if session.Interception != nil {
+		return session.Interception.Delivered
+	}
+	return *session.Measurement
+}
+
+// TamperBits lets Eve flip Alice's classical bits while she holds them on the
+// intercept step; flip marks the bits to invert, such as "01", and "00"
+// passes them on unchanged. A new choice replaces the previous one.
+func (s *TeleportationService) TamperBits(id string, token string, flip string) (*teleportation.SessionState, error) {
+	if _, ok := teleportation.NewInterception(teleportation.Measurement{}, flip); !ok {
+		return nil, errors.New("invalid flip")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions.Get(id)
+	if !ok {
+		return nil, errors.New("session not found")
+	}
+
+	role, err := s.validateTokenLocked(session, token)
+	if err != nil {
+		return nil, err
+	}
+	protocol, err := s.protocolFor(session)
+	if err != nil {
+		return nil, err
+	}
+	if current := session.CurrentStep(); current.Key != teleportation.StepIntercept || session.Measurement == nil || !protocol.Allowed(current, role) {
+		return nil, errors.New("role not permitted for step")
+	}
+
+	interception := interceptLocked(session, flip)
+	session.Record(teleportation.Event{
+		Type:    teleportation.EventBitsTampered,
+		Actor:   role,
+		Payload: teleportation.EventPayload{Step: session.StepIndex, Interception: &interception},
+	})
+	if err := s.saveLocked(session); err != nil {
+		return nil, err
+	}
+
+	s.broadcastLocked(session)
+	return session, nil
+}
+
+// maskInterception hides Eve's work in view from everyone but her until the
+// run is complete; until then Bob and the audience see the bits that reached him.
+func maskInterception(session *teleportation.SessionState, view *teleportation.SessionState, role qubit.Role) {
+	if session.Interception == nil || role == qubit.RoleEve || stepReached(session, teleportation.StepComplete) {
+		return
+	}
+	view.Interception = nil
+	if view.Measurement != nil && role != qubit.RoleAlice {
+		delivered := session.Interception.Delivered
+		view.Measurement = &delivered
+	}
+}
@@ -0,0 +1,173 @@
+package service
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+type eavesdropRun struct {
+	service         *TeleportationService
+	session         *teleportation.SessionState
+	alice, bob, eve string
+}
+
+// startEavesdrop creates a teleportation session with an eavesdropper and
+// advances it to the intercept step.
+func startEavesdrop(t *testing.T, seed int64, preset string) eavesdropRun {
+	t.Helper()
+	state, _ := qubit.Preset(preset)
+	service := NewTeleportationService(WithSeed(seed))
+	session, err := service.CreateSessionWithConfig(teleportation.Config{InitialState: &state, Eavesdropper: true})
+	if err != nil {
+		t.Fatalf("expected a session with eve, got %v", err)
+	}
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+	bob, _ := service.JoinSession(session.ID, qubit.RoleBob, "")
+	eve, err := service.JoinSession(session.ID, qubit.RoleEve, "")
+	if err != nil {
+		t.Fatalf("expected eve to join, got %v", err)
+	}
+	for _, token := range []string{alice.Token, alice.Token, alice.Token} {
+		if _, err := service.AdvanceStep(session.ID, token); err != nil {
+			t.Fatalf("expected alice to measure, got %v", err)
+		}
+	}
+	if session.CurrentStep().Key != teleportation.StepIntercept {
+		t.Fatalf("expected the bits in eve's hands, got %s", session.CurrentStep().Key)
+	}
+	return eavesdropRun{service: service, session: session, alice: alice.Token, bob: bob.Token, eve: eve.Token}
+}
+
+func TestEveReadingBitsLearnsNothing(t *testing.T) {
+	for _, preset := range []string{"0", "+", "i"} {
+		run := startEavesdrop(t, 9, preset)
+		eveView, _ := run.service.SessionView(run.session.ID, run.eve)
+		if eveView.Measurement == nil || math.Abs(eveView.Measurement.Probability-0.25) > 1e-9 {
+			t.Fatalf("expected eve to read bits that are uniform for |%s>, got %+v", preset, eveView.Measurement)
+		}
+		if !eveView.Qubits[0].Hidden || !eveView.Qubits[1].Hidden {
+			t.Fatal("expected eve to hold no qubit")
+		}
+
+		_, _ = run.service.AdvanceStep(run.session.ID, run.eve)
+		_, _ = run.service.AdvanceStep(run.session.ID, run.bob)
+		_, _ = run.service.ApplyCorrection(run.session.ID, run.bob, run.session.Measurement.Correction)
+		if run.session.Interception.FidelityDrop != 0 || math.Abs(run.session.Correction.Fidelity-1) > 1e-9 {
+			t.Fatalf("expected reading alone to cost nothing, got %+v", run.session.Interception)
+		}
+	}
+}
+
+func TestEveFlippingBitsCorruptsTheState(t *testing.T) {
+	cases := []struct {
+		preset, flip string
+		drop         float64
+	}{
+		{"0", "01", 1},
+		{"0", "10", 0},
+		{"+", "10", 1},
+		{"+", "11", 1},
+	}
+	for _, tc := range cases {
+		run := startEavesdrop(t, 4, tc.preset)
+		if _, err := run.service.TamperBits(run.session.ID, run.bob, tc.flip); err == nil {
+			t.Fatal("expected only eve to tamper")
+		}
+		if _, err := run.service.TamperBits(run.session.ID, run.eve, "2"); err == nil || err.Error() != "invalid flip" {
+			t.Fatalf("expected an invalid flip to be refused, got %v", err)
+		}
+		if _, err := run.service.TamperBits(run.session.ID, run.eve, tc.flip); err != nil {
+			t.Fatalf("expected eve to flip %s, got %v", tc.flip, err)
+		}
+		if got := run.session.Interception.FidelityDrop; math.Abs(got-tc.drop) > 1e-9 {
+			t.Fatalf("expected flipping %s on |%s> to cost %.0f, got %f", tc.flip, tc.preset, tc.drop, got)
+		}
+		_, _ = run.service.AdvanceStep(run.session.ID, run.eve)
+		if _, err := run.service.TamperBits(run.session.ID, run.eve, "00"); err == nil {
+			t.Fatal("expected the bits to be out of eve's reach once sent")
+		}
+		_, _ = run.service.AdvanceStep(run.session.ID, run.bob)
+
+		bobView, _ := run.service.SessionView(run.session.ID, run.bob)
+		delivered := run.session.Interception.Delivered
+		if bobView.Interception != nil || *bobView.Measurement != delivered {
+			t.Fatalf("expected bob to see only the delivered bits, got %+v", bobView.Measurement)
+		}
+		neutral := false
+		for _, line := range bobView.Log {
+			if strings.Contains(line, "изменил") {
+				t.Fatalf("expected the log not to tell bob about the flip, got %q", line)
+			}
+			neutral = neutral || line == "Ева решила, какие биты передать Бобу"
+		}
+		if !neutral {
+			t.Fatalf("expected a neutral line for eve's choice, got %v", bobView.Log)
+		}
+		_, _ = run.service.ApplyCorrection(run.session.ID, run.bob, delivered.Correction)
+		if run.session.Correction.Correct || math.Abs(run.session.Correction.Fidelity-(1-tc.drop)) > 1e-9 {
+			t.Fatalf("expected bob's correction to follow the tampered bits, got %+v", run.session.Correction)
+		}
+		_, _ = run.service.AdvanceStep(run.session.ID, run.bob)
+		bobView, _ = run.service.SessionView(run.session.ID, run.bob)
+		if bobView.Interception == nil || *bobView.Measurement != *run.session.Measurement {
+			t.Fatal("expected the interception to be revealed once the run is complete")
+		}
+
+		rebuilt, err := run.service.Rebuild(run.session.Events)
+		if err != nil || *rebuilt.Interception != *run.session.Interception || *rebuilt.Correction != *run.session.Correction {
+			t.Fatalf("expected the tampered run to replay, got %+v, %v", rebuilt, err)
+		}
+	}
+}
+
+func TestEveOnlyExistsWhenConfigured(t *testing.T) {
+	service := NewTeleportationService()
+	session, _ := service.CreateSession()
+	if _, err := service.JoinSession(session.ID, qubit.RoleEve, ""); err == nil {
+		t.Fatal("expected no eve without an eavesdropper")
+	}
+	for _, step := range session.Steps {
+		if step.Key == teleportation.StepIntercept {
+			t.Fatal("expected no intercept step without an eavesdropper")
+		}
+	}
+}
+
+func TestEveAddsNoNoiseOfHerOwn(t *testing.T) {
+	state, _ := qubit.Preset("+")
+	noise := &teleportation.NoiseProfile{Channel: "depolarizing", Probability: 0.1}
+	fidelity := make(map[bool]float64)
+	for _, eavesdropper := range []bool{false, true} {
+		service := NewTeleportationService(WithSeed(4))
+		session, _ := service.CreateSessionWithConfig(teleportation.Config{InitialState: &state, Noise: noise, Eavesdropper: eavesdropper})
+		tokens := make(map[qubit.Role]string)
+		for role := range session.Participants {
+			p, _ := service.JoinSession(session.ID, role, "")
+			tokens[role] = p.Token
+		}
+		for session.CurrentStep().Key != teleportation.StepReconstruct {
+			actor := qubit.RoleAlice
+			switch session.CurrentStep().Key {
+			case teleportation.StepIntercept:
+				actor = qubit.RoleEve
+			case teleportation.StepSend:
+				actor = qubit.RoleBob
+			}
+			if _, err := service.AdvanceStep(session.ID, tokens[actor]); err != nil {
+				t.Fatalf("expected %s to advance, got %v", actor, err)
+			}
+		}
+		corrected, err := service.ApplyCorrection(session.ID, tokens[qubit.RoleBob], session.Measurement.Correction)
+		if err != nil {
+			t.Fatalf("expected correction to succeed, got %v", err)
+		}
+		fidelity[eavesdropper] = corrected.Correction.Fidelity
+	}
+	if fidelity[false] >= 1-1e-9 || math.Abs(fidelity[true]-fidelity[false]) > 1e-9 {
+		t.Fatalf("expected a passive eve to leave the noisy fidelity unchanged, got %v", fidelity)
+	}
+}
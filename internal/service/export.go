@@ -0,0 +1,154 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+	"quantum-teleport/pkg/utils"
+)
+
+// ExportVersion is the bundle format written by ExportSession.
+const ExportVersion = 1
+
+// maxImportEvents bounds the events a bundle may carry, since every one of
+// them is replayed on import.
+const maxImportEvents = 10000
+
+// ExportBundle is a portable record of a session: what it was created with and
+// everything that happened in it. A bundle without events describes a
+// scenario to start from scratch.
+type ExportBundle struct {
+	Version      int                   `json:"version"`
+	SessionID    string                `json:"sessionId,omitempty"`
+	ExportedAt   time.Time             `json:"exportedAt"`
+	Config       teleportation.Config  `json:"config"`
+	Seed         int64                 `json:"seed"`
+	InitialState *qubit.BlochState     `json:"initialState,omitempty"`
+	Events       []teleportation.Event `json:"events"`
+	// FinalFidelity is the fidelity of Bob's last correction, if any.
+	FinalFidelity *float64 `json:"finalFidelity,omitempty"`
+}
+
+// ExportSession returns the bundle of a session to its host.
+func (s *TeleportationService) ExportSession(id string, hostToken string) (ExportBundle, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions.Get(id)
+	if !ok {
+		return ExportBundle{}, errors.New("session not found")
+	}
+	if hostToken == "" || hostToken != session.HostToken {
+		return ExportBundle{}, errors.New("unknown host token")
+	}
+	if len(session.Events) == 0 || session.Events[0].Payload.Config == nil {
+		return ExportBundle{}, errors.New("session has no history")
+	}
+
+	created := session.Events[0].Payload
+	bundle := ExportBundle{
+		Version:    ExportVersion,
+		SessionID:  session.ID,
+		ExportedAt: time.Now(),
+		Config:     *created.Config,
+		Events:     append([]teleportation.Event(nil), session.Events...),
+	}
+	// Protocols without an unknown state export none, so the bundle still
+	// imports as a scenario once its events are dropped.
+	withState := *created.Config
+	withState.InitialState = created.State
+	if protocol, err := s.protocolFor(session); err == nil && validateConfig(protocol, withState) == nil {
+		bundle.InitialState = created.State
+	}
+	if created.Config.Seed != nil {
+		bundle.Seed = *created.Config.Seed
+	}
+	if session.Correction != nil {
+		fidelity := session.Correction.Fidelity
+		bundle.FinalFidelity = &fidelity
+	}
+	return bundle, nil
+}
+
+// ImportSession recreates a bundle as a new session with its own ID, host
+// token and free roles. Events are replayed from the seed, so the run can be
+// replayed, rewound or reset; a bundle without events starts a fresh session
+// with its config, seed and initial state.
+func (s *TeleportationService) ImportSession(bundle ExportBundle) (*teleportation.SessionState, error) {
+	if bundle.Version != ExportVersion {
+		return nil, errors.New("unsupported export version")
+	}
+	if len(bundle.Events) > maxImportEvents {
+		return nil, errors.New("too many events")
+	}
+	config := bundle.Config
+	if config.Seed != nil && *config.Seed != bundle.Seed {
+		return nil, errors.New("config seed does not match bundle seed")
+	}
+	seed := bundle.Seed
+	config.Seed = &seed
+
+	if len(bundle.Events) == 0 {
+		if bundle.InitialState != nil {
+			config.InitialState = bundle.InitialState
+		}
+		return s.CreateSessionWithConfig(config)
+	}
+
+	created := bundle.Events[0].Payload
+	if created.Config != nil {
+		if created.Config.Seed == nil || *created.Config.Seed != seed {
+			return nil, errors.New("events were recorded with another seed")
+		}
+		// The events are what gets replayed, so a config or state that
+		// contradicts them would describe a session that never ran.
+		if !sameConfig(config, *created.Config) {
+			return nil, errors.New("config does not match events")
+		}
+		if bundle.InitialState != nil && (created.State == nil || *bundle.InitialState != *created.State) {
+			return nil, errors.New("initial state does not match events")
+		}
+	}
+	session, err := s.Rebuild(bundle.Events)
+	if err != nil {
+		return nil, errors.New("invalid events: " + err.Error())
+	}
+	id, err := utils.NewID()
+	if err != nil {
+		return nil, err
+	}
+	hostToken, err := utils.NewID()
+	if err != nil {
+		return nil, err
+	}
+	session.ID = id
+	session.HostToken = hostToken
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for role, p := range session.Participants {
+		session.Participants[role] = teleportation.Participant{Role: p.Role, LastSeen: now}
+	}
+	if err := s.saveLocked(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// sameConfig reports whether two configs with the same seed ask for the same
+// session; an empty protocol is the default one.
+func sameConfig(a, b teleportation.Config) bool {
+	if a.Protocol == "" {
+		a.Protocol = teleportation.ProtocolTeleportation
+	}
+	if a.Protocol != b.Protocol || a.Relays != b.Relays || a.Qubits != b.Qubits || a.Eavesdropper != b.Eavesdropper {
+		return false
+	}
+	if (a.Noise == nil) != (b.Noise == nil) || a.Noise != nil && *a.Noise != *b.Noise {
+		return false
+	}
+	return (a.InitialState == nil) == (b.InitialState == nil) && (a.InitialState == nil || *a.InitialState == *b.InitialState)
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+func TestExportImportRecreatesSessionInFreshLobby(t *testing.T) {
+	source := NewTeleportationService(WithSeed(5))
+	session, _ := source.CreateSession()
+	alice, _ := source.JoinSession(session.ID, qubit.RoleAlice, "")
+	bob, _ := source.JoinSession(session.ID, qubit.RoleBob, "")
+	for _, token := range []string{alice.Token, alice.Token, alice.Token, bob.Token} {
+		if _, err := source.AdvanceStep(session.ID, token); err != nil {
+			t.Fatalf("expected advance to succeed, got %v", err)
+		}
+	}
+	if _, err := source.ApplyCorrection(session.ID, bob.Token, session.Measurement.Correction); err != nil {
+		t.Fatalf("expected correction to succeed, got %v", err)
+	}
+	if _, err := source.AdvanceStep(session.ID, bob.Token); err != nil {
+		t.Fatalf("expected advance to succeed, got %v", err)
+	}
+
+	if _, err := source.ExportSession(session.ID, alice.Token); err == nil {
+		t.Fatal("expected export to require the host token")
+	}
+	bundle, err := source.ExportSession(session.ID, session.HostToken)
+	if err != nil {
+		t.Fatalf("expected export to succeed, got %v", err)
+	}
+	if bundle.Version != ExportVersion || bundle.Seed != *session.Config.Seed || bundle.InitialState == nil || *bundle.InitialState != session.HiddenState {
+		t.Fatalf("expected versioned bundle with seed and initial state, got %+v", bundle)
+	}
+	if bundle.FinalFidelity == nil || *bundle.FinalFidelity < 1-1e-9 {
+		t.Fatalf("expected the final fidelity to be exported, got %v", bundle.FinalFidelity)
+	}
+
+	target := NewTeleportationService()
+	imported, err := target.ImportSession(bundle)
+	if err != nil {
+		t.Fatalf("expected import to succeed, got %v", err)
+	}
+	if imported.ID == session.ID || imported.HostToken == "" || imported.HostToken == session.HostToken {
+		t.Fatal("expected a new session ID and host token")
+	}
+	if imported.StepIndex != session.StepIndex || !reflect.DeepEqual(imported.Measurement, session.Measurement) || !reflect.DeepEqual(imported.Log, session.Log) {
+		t.Fatalf("expected the recorded run to be restored, got step %d, %+v", imported.StepIndex, imported.Measurement)
+	}
+	for role, p := range imported.Participants {
+		if p.Taken || p.Token != "" {
+			t.Fatalf("expected %s to be free in the new lobby, got %+v", role, p)
+		}
+	}
+	if _, err := target.HostControl(imported.ID, imported.HostToken, HostCommand{Action: HostReset}); err != nil {
+		t.Fatalf("expected the imported session to be reset by its new host, got %v", err)
+	}
+
+	scenario := ExportBundle{Version: ExportVersion, Seed: 42, InitialState: bundle.InitialState}
+	fresh, err := target.ImportSession(scenario)
+	if err != nil {
+		t.Fatalf("expected a scenario without events to start a session, got %v", err)
+	}
+	if fresh.StepIndex != 0 || fresh.HiddenState != *bundle.InitialState || *fresh.Config.Seed != 42 {
+		t.Fatalf("expected a fresh session from the scenario, got %+v", fresh.Config)
+	}
+
+	bundle.Version = 2
+	if _, err := target.ImportSession(bundle); err == nil {
+		t.Fatal("expected unknown versions to be rejected")
+	}
+	bundle.Version = ExportVersion
+	bundle.Events = append([]teleportation.Event(nil), bundle.Events...)
+	for i, e := range bundle.Events {
+		if e.Type == teleportation.EventCorrectionApplied {
+			tampered := *e.Payload.Correction
+			tampered.Applied = "Q"
+			bundle.Events[i].Payload.Correction = &tampered
+		}
+	}
+	if _, err := target.ImportSession(bundle); err == nil {
+		t.Fatal("expected tampered events to be rejected")
+	}
+}
+
+func TestImportRefusesStepsSkippingRequiredActions(t *testing.T) {
+	configs := []teleportation.Config{
+		{Protocol: teleportation.ProtocolTeleportation},
+		{Protocol: teleportation.ProtocolTeleportation, Eavesdropper: true},
+		{Protocol: teleportation.ProtocolSuperdense},
+		{Protocol: teleportation.ProtocolSwapping},
+		{Protocol: teleportation.ProtocolChain, Relays: 2},
+		{Protocol: teleportation.ProtocolControlled},
+	}
+	for _, config := range configs {
+		service := NewTeleportationService(WithSeed(7))
+		session, err := service.CreateSessionWithConfig(config)
+		if err != nil {
+			t.Fatalf("expected a %s session, got %v", config.Protocol, err)
+		}
+		// Only step transitions: no correction or encoding was ever recorded.
+		events := []teleportation.Event{session.Events[0]}
+		for step := 1; step < len(session.Steps); step++ {
+			events = append(events, teleportation.Event{
+				Type:    teleportation.EventStepAdvanced,
+				Payload: teleportation.EventPayload{Step: step},
+			})
+		}
+		bundle := ExportBundle{Version: ExportVersion, Config: *events[0].Payload.Config, Seed: *session.Config.Seed, Events: events}
+		if _, err := service.ImportSession(bundle); err == nil {
+			t.Fatalf("expected %s steps without the required actions to be rejected", config.Protocol)
+		}
+	}
+}
+
+func TestImportRefusesBundlesContradictingTheirEvents(t *testing.T) {
+	service := NewTeleportationService(WithSeed(8))
+	session, _ := service.CreateSession()
+	bundle, err := service.ExportSession(session.ID, session.HostToken)
+	if err != nil {
+		t.Fatalf("expected export to succeed, got %v", err)
+	}
+
+	relays := bundle
+	relays.Config.Protocol = teleportation.ProtocolChain
+	relays.Config.Relays = 3
+	noisy := bundle
+	noisy.Config.Noise = &teleportation.NoiseProfile{Channel: "depolarizing", Probability: 0.1}
+	plus, _ := qubit.Preset("+")
+	moved := bundle
+	moved.InitialState = &plus
+	for name, c := range map[string]struct {
+		bundle ExportBundle
+		err    string
+	}{
+		"protocol": {relays, "config does not match events"},
+		"noise":    {noisy, "config does not match events"},
+		"state":    {moved, "initial state does not match events"},
+	} {
+		if _, err := service.ImportSession(c.bundle); err == nil || err.Error() != c.err {
+			t.Fatalf("expected a contradicting %s to be refused with %q, got %v", name, c.err, err)
+		}
+	}
+	if _, err := service.ImportSession(bundle); err != nil {
+		t.Fatalf("expected the untouched bundle to import, got %v", err)
+	}
+}
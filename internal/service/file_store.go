@@ -0,0 +1,203 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"quantum-teleport/internal/domain/quantum"
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+	"quantum-teleport/pkg/utils"
+)
+
+// FileStore keeps sessions in memory and writes each one through to a JSON
+// file in dir, so sessions and tokens survive a restart.
+type FileStore struct {
+	memory *MemoryStore
+	dir    string
+}
+
+// sessionRecord carries the fields SessionState hides from API clients.
+type sessionRecord struct {
+	Session     *teleportation.SessionState  `json:"session"`
+	HiddenState qubit.BlochState             `json:"hiddenState"`
+	HostToken   string                       `json:"hostToken"`
+	Register    quantum.Snapshot             `json:"register"`
+	Random      *utils.SeededRand            `json:"random"`
+	History     []teleportation.StepSnapshot `json:"history,omitempty"`
+	Events      []teleportation.Event        `json:"events"`
+	Tokens      map[qubit.Role]string        `json:"tokens"`
+	LastSeen    map[qubit.Role]time.Time     `json:"lastSeen"`
+	Observers   map[string]time.Time         `json:"observers,omitempty"`
+}
+
+// NewFileStore opens dir, creating it when missing, and loads every stored session.
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	store := &FileStore{memory: NewMemoryStore(), dir: dir}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		session, err := decodeRecord(data)
+		if err != nil {
+			return nil, errors.New("corrupt session file " + filepath.Base(path) + ": " + err.Error())
+		}
+		_ = store.memory.Save(session)
+	}
+	return store, nil
+}
+
+// Get returns the session with the given ID.
+func (f *FileStore) Get(id string) (*teleportation.SessionState, bool) {
+	return f.memory.Get(id)
+}
+
+// Save atomically rewrites the session file and then stores the session. The
+// service mutates sessions in place before saving them, so when the write fails
+// Save puts the last written version back in memory: a failed save leaves the
+// session as it was before the change, and a session never written is dropped.
+func (f *FileStore) Save(session *teleportation.SessionState) error {
+	if err := f.write(session); err != nil {
+		f.rollback(session)
+		return err
+	}
+	return f.memory.Save(session)
+}
+
+// Delete removes the session and its file.
+func (f *FileStore) Delete(id string) error {
+	_ = f.memory.Delete(id)
+	if err := os.Remove(f.path(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// List returns all stored sessions.
+func (f *FileStore) List() []*teleportation.SessionState {
+	return f.memory.List()
+}
+
+func (f *FileStore) write(session *teleportation.SessionState) error {
+	data, err := encodeRecord(session)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(f.dir, session.ID+".*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path(session.ID))
+}
+
+// rollback replaces the in-memory session with its last written version. Open
+// sockets are real, so connection flags are kept from the live session. When
+// the file cannot be read for any reason other than never having been written,
+// the live session stays as it is.
+func (f *FileStore) rollback(session *teleportation.SessionState) {
+	data, err := os.ReadFile(f.path(session.ID))
+	if errors.Is(err, os.ErrNotExist) {
+		_ = f.memory.Delete(session.ID)
+		return
+	}
+	if err != nil {
+		return
+	}
+	previous, err := decodeRecord(data)
+	if err != nil {
+		return
+	}
+	for role, p := range previous.Participants {
+		p.Connected = session.Participants[role].Connected
+		previous.Participants[role] = p
+	}
+	_ = f.memory.Save(previous)
+}
+
+func (f *FileStore) path(id string) string {
+	return filepath.Join(f.dir, filepath.Base(strings.ReplaceAll(id, "..", ""))+".json")
+}
+
+func encodeRecord(session *teleportation.SessionState) ([]byte, error) {
+	record := sessionRecord{
+		Session:     session,
+		HiddenState: session.HiddenState,
+		HostToken:   session.HostToken,
+		Random:      session.Random,
+		History:     session.History,
+		Events:      session.Events,
+		Tokens:      make(map[qubit.Role]string),
+		LastSeen:    make(map[qubit.Role]time.Time),
+	}
+	if session.Register != nil {
+		record.Register = quantum.Capture(session.Register)
+	}
+	if len(session.Observers) > 0 {
+		record.Observers = make(map[string]time.Time, len(session.Observers))
+		for token, o := range session.Observers {
+			record.Observers[token] = o.JoinedAt
+		}
+	}
+	for role, p := range session.Participants {
+		record.Tokens[role] = p.Token
+		record.LastSeen[role] = p.LastSeen
+	}
+	return json.Marshal(record)
+}
+
+func decodeRecord(data []byte) (*teleportation.SessionState, error) {
+	var record sessionRecord
+	if err := json.Unmarshal(data, &record); err != nil {
+		return nil, err
+	}
+	session := record.Session
+	if session == nil || session.ID == "" {
+		return nil, errors.New("missing session")
+	}
+	register, err := record.Register.Restore()
+	if err != nil {
+		return nil, err
+	}
+	session.Register = register
+	session.HiddenState = record.HiddenState
+	session.HostToken = record.HostToken
+	session.Random = record.Random
+	session.History = record.History
+	session.Events = record.Events
+	if len(record.Observers) > 0 {
+		session.Observers = make(map[string]teleportation.Observer, len(record.Observers))
+		for token, joined := range record.Observers {
+			session.Observers[token] = teleportation.Observer{Token: token, JoinedAt: joined}
+		}
+	}
+	// Sockets do not survive a restart; roles stay reserved by token until their TTL runs out.
+	for role, p := range session.Participants {
+		p.Token = record.Tokens[role]
+		p.LastSeen = record.LastSeen[role]
+		p.Connected = false
+		session.Participants[role] = p
+	}
+	return session, nil
+}
@@ -0,0 +1,104 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+func TestFileStoreSurvivesRestart(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("expected store to open, got %v", err)
+	}
+	service := NewTeleportationService(WithStore(store))
+	session, _ := service.CreateSession()
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+	bob, _ := service.JoinSession(session.ID, qubit.RoleBob, "")
+	for _, token := range []string{alice.Token, alice.Token, alice.Token} {
+		if _, err := service.AdvanceStep(session.ID, token); err != nil {
+			t.Fatalf("expected advance to succeed, got %v", err)
+		}
+	}
+
+	reopened, err := NewFileStore(dir)
+	if err != nil {
+		t.Fatalf("expected store to reopen, got %v", err)
+	}
+	restarted := NewTeleportationService(WithStore(reopened))
+
+	restored, err := restarted.GetSession(session.ID)
+	if err != nil {
+		t.Fatalf("expected session to survive restart, got %v", err)
+	}
+	if restored.StepIndex != 3 || restored.Measurement == nil || restored.HiddenState != session.HiddenState {
+		t.Fatalf("expected progress to be restored, got step %d measurement %+v", restored.StepIndex, restored.Measurement)
+	}
+	if len(restored.History) != 4 || restored.History[2].Register.Kind == "" {
+		t.Fatalf("expected step snapshots to be restored, got %d", len(restored.History))
+	}
+	if _, err := restarted.JoinSession(session.ID, qubit.RoleBob, ""); err == nil {
+		t.Fatal("expected bob's role to stay reserved after restart")
+	}
+
+	current, err := restarted.AdvanceStep(session.ID, bob.Token)
+	if err != nil {
+		t.Fatalf("expected bob's token to survive restart, got %v", err)
+	}
+	corrected, err := restarted.ApplyCorrection(session.ID, bob.Token, current.Measurement.Correction)
+	if err != nil {
+		t.Fatalf("expected correction after restart, got %v", err)
+	}
+	if !corrected.Correction.Correct || corrected.Correction.Fidelity < 1-1e-9 {
+		t.Fatalf("expected restored register to teleport perfectly, got %+v", corrected.Correction)
+	}
+
+	if err := reopened.Delete(session.ID); err != nil {
+		t.Fatalf("expected delete to succeed, got %v", err)
+	}
+	if again, _ := NewFileStore(dir); len(again.List()) != 0 {
+		t.Fatalf("expected deleted session to be gone from disk, got %d sessions", len(again.List()))
+	}
+}
+
+func TestFileStoreRollsBackFailedSaves(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("expected store to open, got %v", err)
+	}
+	service := NewTeleportationService(WithStore(store))
+	session, _ := service.CreateSession()
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+	if _, err := service.AdvanceStep(session.ID, alice.Token); err != nil {
+		t.Fatalf("expected advance to succeed, got %v", err)
+	}
+
+	// NaN cannot be encoded, so the write fails after the change was applied in place.
+	live, _ := store.Get(session.ID)
+	live.StepIndex++
+	live.Measurement = &teleportation.Measurement{Probability: math.NaN()}
+	if err := store.Save(live); err == nil {
+		t.Fatal("expected save to fail")
+	}
+	restored, ok := store.Get(session.ID)
+	if !ok || restored.StepIndex != 1 || restored.Measurement != nil {
+		t.Fatalf("expected the failed change to be rolled back, got %+v", restored)
+	}
+	if _, ok := restored.Participants[qubit.RoleAlice]; !ok {
+		t.Fatal("expected alice's role to survive the rollback")
+	}
+	if advanced, err := service.AdvanceStep(session.ID, alice.Token); err != nil || advanced.StepIndex != 2 {
+		t.Fatalf("expected the retried advance to succeed, got %v", err)
+	}
+
+	unsaved := &teleportation.SessionState{ID: "unsaved", Measurement: &teleportation.Measurement{Probability: math.NaN()}}
+	if err := store.Save(unsaved); err == nil {
+		t.Fatal("expected save to fail")
+	}
+	if _, ok := store.Get("unsaved"); ok {
+		t.Fatal("expected a session never written to stay out of the store")
+	}
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// joinAll joins every role of a session and returns the tokens by role.
+func joinAll(t *testing.T, service *TeleportationService, session *teleportation.SessionState) map[qubit.Role]string {
+	t.Helper()
+	tokens := make(map[qubit.Role]string)
+	for role := range session.Participants {
+		p, err := service.JoinSession(session.ID, role, "")
+		if err != nil {
+			t.Fatalf("expected %s to join, got %v", role, err)
+		}
+		tokens[role] = p.Token
+	}
+	return tokens
+}
+
+func TestControlledTeleportationNeedsCharlie(t *testing.T) {
+	seen := make(map[teleportation.Correction]bool)
+	for seed := int64(1); seed <= 16; seed++ {
+		service := NewTeleportationService(WithSeed(seed))
+		session, err := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolControlled})
+		if err != nil {
+			t.Fatalf("expected a controlled session, got %v", err)
+		}
+		if len(session.Participants) != 3 || len(session.Qubits) != 3 {
+			t.Fatalf("expected three roles and three qubits, got %d and %d", len(session.Participants), len(session.Qubits))
+		}
+		tokens := joinAll(t, service, session)
+		alice, bob, charlie := tokens[qubit.RoleAlice], tokens[qubit.RoleBob], tokens[qubit.RoleCharlie]
+
+		for _, token := range []string{charlie, alice, alice} {
+			if _, err := service.AdvanceStep(session.ID, token); err != nil {
+				t.Fatalf("expected alice to measure, got %v", err)
+			}
+		}
+		if _, err := service.AdvanceStep(session.ID, bob); err == nil {
+			t.Fatal("expected only charlie to agree to help")
+		}
+		for _, token := range []string{charlie, bob} {
+			if _, err := service.AdvanceStep(session.ID, token); err != nil {
+				t.Fatalf("expected charlie to measure, got %v", err)
+			}
+		}
+
+		control := session.Control
+		if control == nil || control.Correction != teleportation.ControlledCorrection(*session.Measurement, control.Bit) {
+			t.Fatalf("expected charlie's bit to fix the correction, got %+v", control)
+		}
+		seen[control.Correction] = true
+		if _, err := service.ApplyCorrection(session.ID, charlie, control.Correction); err == nil {
+			t.Fatal("expected only bob to correct")
+		}
+		if _, err := service.ApplyCorrection(session.ID, bob, control.Correction); err != nil {
+			t.Fatalf("expected bob to correct, got %v", err)
+		}
+		if !session.Correction.Correct || math.Abs(session.Correction.Fidelity-1) > 1e-9 {
+			t.Fatalf("expected the state after %s, got %+v", control.Correction, session.Correction)
+		}
+		if _, err := service.AdvanceStep(session.ID, bob); err != nil {
+			t.Fatalf("expected bob to complete, got %v", err)
+		}
+
+		rebuilt, err := service.Rebuild(session.Events)
+		if err != nil || *rebuilt.Control != *control || *rebuilt.Correction != *session.Correction {
+			t.Fatalf("expected the run to replay, got %+v, %v", rebuilt, err)
+		}
+	}
+	if len(seen) != 4 {
+		t.Fatalf("expected all four corrections across seeds, got %v", seen)
+	}
+}
+
+func TestControlledTeleportationFailsWithoutCharliesBit(t *testing.T) {
+	plus, _ := qubit.Preset("+")
+	service := NewTeleportationService(WithSeed(3))
+	session, _ := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolControlled, InitialState: &plus})
+	tokens := joinAll(t, service, session)
+	alice, bob, charlie := tokens[qubit.RoleAlice], tokens[qubit.RoleBob], tokens[qubit.RoleCharlie]
+	for _, token := range []string{alice, alice, alice, charlie, bob} {
+		_, _ = service.AdvanceStep(session.ID, token)
+	}
+
+	if math.Abs(session.Control.FidelityWithout-0.5) > 1e-9 {
+		t.Fatalf("expected alice's bits alone to leave |+> at fidelity 1/2, got %+v", session.Control)
+	}
+	bobView, _ := service.SessionView(session.ID, bob)
+	if bobView.Measurement == nil || bobView.Control == nil || !bobView.Qubits[2].Hidden {
+		t.Fatalf("expected bob to hold both announcements but not charlie's qubit, got %+v", bobView)
+	}
+	wrong := session.Measurement.Correction
+	if wrong == session.Control.Correction {
+		wrong = teleportation.ControlledCorrection(*session.Measurement, 1-session.Control.Bit)
+	}
+	if _, err := service.ApplyCorrection(session.ID, bob, wrong); err != nil {
+		t.Fatalf("expected bob to try a correction, got %v", err)
+	}
+	if session.Correction.Correct || session.Correction.Fidelity > 1-1e-9 {
+		t.Fatalf("expected ignoring charlie's bit to miss the state, got %+v", session.Correction)
+	}
+}
+
+func TestSecretSharingNeedsBobAndCharlieTogether(t *testing.T) {
+	for seed := int64(1); seed <= 6; seed++ {
+		service := NewTeleportationService(WithSeed(seed))
+		session, err := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolSecretSharing, Qubits: 64})
+		if err != nil {
+			t.Fatalf("expected a secret-sharing session, got %v", err)
+		}
+		tokens := joinAll(t, service, session)
+		for _, role := range []qubit.Role{qubit.RoleCharlie, qubit.RoleBob, qubit.RoleAlice} {
+			if _, err := service.AdvanceStep(session.ID, tokens[role]); err != nil {
+				t.Fatalf("expected %s to advance, got %v", role, err)
+			}
+		}
+		if _, err := service.AdvanceStep(session.ID, tokens[qubit.RoleAlice]); err == nil {
+			t.Fatal("expected only bob and charlie to pool their shares")
+		}
+		if _, err := service.AdvanceStep(session.ID, tokens[qubit.RoleCharlie]); err != nil {
+			t.Fatalf("expected charlie to pool the shares, got %v", err)
+		}
+
+		s := session.Sharing
+		if session.CurrentStep().Key != teleportation.StepComplete || s.Rounds != 64 || s.ValidCount != len(s.Valid) || s.KeyLength != s.ValidCount {
+			t.Fatalf("expected a finished run over 64 rounds, got %+v", s.SharingReport)
+		}
+		if s.ValidCount < 16 || s.ValidCount > 48 || s.Errors != 0 {
+			t.Fatalf("expected about half the rounds to be valid and none wrong, got %+v", s.SharingReport)
+		}
+		if s.BobAlone < 0.2 || s.BobAlone > 0.8 || s.CharlieAlone < 0.2 || s.CharlieAlone > 0.8 {
+			t.Fatalf("expected a lone share to guess about half the bits, got %+v", s.SharingReport)
+		}
+
+		rebuilt, err := service.Rebuild(session.Events)
+		if err != nil || rebuilt.Sharing.SharingReport != s.SharingReport || rebuilt.Sharing.AliceBits != s.AliceBits {
+			t.Fatalf("expected the run to replay, got %+v, %v", rebuilt, err)
+		}
+	}
+}
+
+func TestSecretSharingKeepsSharesPrivate(t *testing.T) {
+	service := NewTeleportationService(WithSeed(8))
+	if _, err := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolSecretSharing, Qubits: teleportation.MaxSharingRounds + 1}); err == nil || err.Error() != "invalid qubit count" {
+		t.Fatalf("expected too many rounds to be refused, got %v", err)
+	}
+	session, _ := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolSecretSharing})
+	if session.Sharing.Rounds != teleportation.DefaultSharingRounds {
+		t.Fatalf("expected the default number of rounds, got %+v", session.Sharing)
+	}
+	tokens := joinAll(t, service, session)
+	_, _ = service.AdvanceStep(session.ID, tokens[qubit.RoleAlice])
+
+	bobView, _ := service.SessionView(session.ID, tokens[qubit.RoleBob])
+	if v := bobView.Sharing; v.BobBits == "" || v.AliceBits != "" || v.CharlieBits != "" || v.CharlieBases != "" {
+		t.Fatalf("expected bob to see only his own share, got %+v", v)
+	}
+	_, _ = service.AdvanceStep(session.ID, tokens[qubit.RoleAlice])
+	observer, _ := service.SessionView(session.ID, "")
+	if v := observer.Sharing; v.AliceBases == "" || v.CharlieBases == "" || v.AliceBits != "" || v.Valid == nil {
+		t.Fatalf("expected the bases but not the bits to be public after sifting, got %+v", v)
+	}
+	_, _ = service.AdvanceStep(session.ID, tokens[qubit.RoleBob])
+	charlieView, _ := service.SessionView(session.ID, tokens[qubit.RoleCharlie])
+	if v := charlieView.Sharing; v.BobBits == "" || v.CharlieBits == "" || v.AliceBits != "" {
+		t.Fatalf("expected charlie to see both shares once pooled, got %+v", v)
+	}
+	aliceView, _ := service.SessionView(session.ID, tokens[qubit.RoleAlice])
+	if v := aliceView.Sharing; v.BobBits != "" || v.AliceBits == "" {
+		t.Fatalf("expected alice to keep only her own bits, got %+v", v)
+	}
+}
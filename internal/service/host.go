@@ -0,0 +1,133 @@
+package service
+
+import (
+	"errors"
+
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// HostAction names an instructor control applied to a whole session.
+type HostAction string
+
+const (
+	// HostReset returns the session to the first step with the same unknown state.
+	HostReset HostAction = "reset"
+	// HostRewind returns the session to an earlier step.
+	HostRewind HostAction = "rewind"
+	// HostKick releases a participant role and closes its sockets.
+	HostKick HostAction = "kick"
+	// HostRegenerate draws a new unknown state and resets the session.
+	HostRegenerate HostAction = "regenerate"
+	// HostLock stops new participants from joining.
+	HostLock HostAction = "lock"
+	// HostUnlock lets new participants join again.
+	HostUnlock HostAction = "unlock"
+)
+
+// HostCommand is an instructor control with its arguments: Step for rewind,
+// Role for kick.
+type HostCommand struct {
+	Action HostAction
+	Step   int
+	Role   qubit.Role
+}
+
+// InfoRoleKicked is pushed to listeners when the host releases a role.
+const InfoRoleKicked = "role_kicked"
+
+// HostControl applies an instructor command authorised by the session's host token.
+// Reset and rewind restore the snapshot taken on entering the target step,
+// including its log and measurement. Reset, rewind and regenerate keep the
+// roles, so the same pair continues from the restored step; kick frees a role
+// for the next student.
+func (s *TeleportationService) HostControl(id string, hostToken string, cmd HostCommand) (*teleportation.SessionState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions.Get(id)
+	if !ok {
+		return nil, errors.New("session not found")
+	}
+	if hostToken == "" || hostToken != session.HostToken {
+		return nil, errors.New("unknown host token")
+	}
+
+	if cmd.Action == HostKick {
+		participant, exists := session.Participants[cmd.Role]
+		if !exists {
+			return nil, errors.New("role unsupported")
+		}
+		if participant.Token == "" {
+			return nil, errors.New("role not taken")
+		}
+		s.releaseRoleLocked(session, cmd.Role)
+		session.Record(teleportation.Event{
+			Type:    teleportation.EventRoleLeft,
+			Actor:   qubit.RoleInstructor,
+			Payload: teleportation.EventPayload{Step: session.StepIndex, Role: cmd.Role, Reason: teleportation.LeaveKicked},
+		})
+		s.infoLocked(session, InfoEvent{Event: InfoRoleKicked, Role: cmd.Role}, nil)
+	} else {
+		protocol, err := s.protocolFor(session)
+		if err != nil {
+			return nil, err
+		}
+		if err := hostActionLocked(protocol, session, cmd); err != nil {
+			return nil, err
+		}
+		payload := teleportation.EventPayload{
+			Step:   session.StepIndex,
+			Title:  session.CurrentStep().Title,
+			Action: string(cmd.Action),
+		}
+		if cmd.Action == HostRegenerate {
+			state := session.HiddenState
+			payload.State = &state
+		}
+		session.Record(teleportation.Event{Type: teleportation.EventHostAction, Actor: qubit.RoleInstructor, Payload: payload})
+	}
+
+	if err := s.saveLocked(session); err != nil {
+		return nil, err
+	}
+	s.broadcastLocked(session)
+	return session, nil
+}
+
+// hostActionLocked applies every host command except kick, which also has to
+// close sockets. It is shared with Rebuild.
+func hostActionLocked(protocol Protocol, session *teleportation.SessionState, cmd HostCommand) error {
+	switch cmd.Action {
+	case HostReset:
+		return session.Rewind(0)
+	case HostRewind:
+		if cmd.Step < 0 || cmd.Step >= session.StepIndex {
+			return errors.New("invalid step")
+		}
+		return session.Rewind(cmd.Step)
+	case HostRegenerate:
+		session.Config.InitialState = nil
+		restartLocked(protocol, session)
+	case HostLock:
+		session.Locked = true
+	case HostUnlock:
+		session.Locked = false
+	default:
+		return errors.New("invalid host action")
+	}
+	return nil
+}
+
+// restartLocked prepares the first step of the protocol again and starts a new
+// history from it.
+func restartLocked(protocol Protocol, session *teleportation.SessionState) {
+	session.Measurement = nil
+	session.Correction = nil
+	session.ProtocolState = teleportation.ProtocolState{}
+	session.StepIndex = 0
+	session.History = nil
+	protocol.Prepare(session)
+	protocol.Sync(session)
+	session.Checkpoint()
+}
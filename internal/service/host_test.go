@@ -0,0 +1,207 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+func TestHostControlsRequireHostToken(t *testing.T) {
+	service := NewTeleportationService()
+	session, _ := service.CreateSession()
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+
+	if session.HostToken == "" {
+		t.Fatal("expected a host token on creation")
+	}
+	if _, err := service.HostControl(session.ID, alice.Token, HostCommand{Action: HostReset}); err == nil {
+		t.Fatal("expected a participant token to be rejected")
+	}
+	if _, err := service.HostControl(session.ID, session.HostToken, HostCommand{Action: "explode"}); err == nil {
+		t.Fatal("expected an unknown action to be rejected")
+	}
+	if _, err := service.AdvanceStep(session.ID, session.HostToken); err == nil {
+		t.Fatal("expected the host not to act as a participant")
+	}
+	view, err := service.SessionView(session.ID, session.HostToken)
+	if err != nil || view.Config.Seed == nil {
+		t.Fatalf("expected the host to get the instructor view, got %+v, %v", view, err)
+	}
+}
+
+func TestHostResetAndRewindKeepRoles(t *testing.T) {
+	service := NewTeleportationService()
+	preset, _ := qubit.Preset("+")
+	session, _ := service.CreateSessionWithConfig(teleportation.Config{InitialState: &preset})
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+	for i := 0; i < 3; i++ {
+		if _, err := service.AdvanceStep(session.ID, alice.Token); err != nil {
+			t.Fatalf("expected advance to succeed, got %v", err)
+		}
+	}
+	if session.Measurement == nil {
+		t.Fatal("expected a measurement after the measure step")
+	}
+
+	if _, err := service.HostControl(session.ID, session.HostToken, HostCommand{Action: HostRewind, Step: 3}); err == nil {
+		t.Fatal("expected rewinding to the current step to be rejected")
+	}
+	updated, err := service.HostControl(session.ID, session.HostToken, HostCommand{Action: HostRewind, Step: 1})
+	if err != nil {
+		t.Fatalf("expected rewind to succeed, got %v", err)
+	}
+	if updated.StepIndex != 1 || updated.Measurement != nil {
+		t.Fatalf("expected step 1 without a measurement, got step %d, %+v", updated.StepIndex, updated.Measurement)
+	}
+	if updated.Qubits[1].State != "Запутанная пара готова" {
+		t.Fatalf("expected the combine transition to be replayed, got %q", updated.Qubits[1].State)
+	}
+
+	updated, err = service.HostControl(session.ID, session.HostToken, HostCommand{Action: HostReset})
+	if err != nil {
+		t.Fatalf("expected reset to succeed, got %v", err)
+	}
+	if updated.StepIndex != 0 || math.Abs(updated.Qubits[0].Bloch.Theta-preset.Theta) > 1e-9 {
+		t.Fatalf("expected the prepared state at step 0, got step %d, %+v", updated.StepIndex, updated.Qubits[0].Bloch)
+	}
+	if _, err := service.AdvanceStep(session.ID, alice.Token); err != nil {
+		t.Fatalf("expected alice to keep her role after reset, got %v", err)
+	}
+}
+
+func TestHostRegenerateDrawsNewState(t *testing.T) {
+	service := NewTeleportationService(WithSeed(7))
+	session, _ := service.CreateSession()
+	before := session.HiddenState
+
+	updated, err := service.HostControl(session.ID, session.HostToken, HostCommand{Action: HostRegenerate})
+	if err != nil {
+		t.Fatalf("expected regenerate to succeed, got %v", err)
+	}
+	if updated.HiddenState == before {
+		t.Fatal("expected a new unknown state")
+	}
+	if updated.StepIndex != 0 || updated.Config.InitialState != nil {
+		t.Fatalf("expected a fresh random session, got step %d, %+v", updated.StepIndex, updated.Config.InitialState)
+	}
+}
+
+func TestHostKickAndLock(t *testing.T) {
+	service := NewTeleportationService()
+	session, _ := service.CreateSession()
+	bob, _ := service.JoinSession(session.ID, qubit.RoleBob, "")
+
+	if _, err := service.HostControl(session.ID, session.HostToken, HostCommand{Action: HostKick, Role: qubit.RoleAlice}); err == nil {
+		t.Fatal("expected kicking a free role to fail")
+	}
+	if _, err := service.HostControl(session.ID, session.HostToken, HostCommand{Action: HostKick, Role: qubit.RoleBob}); err != nil {
+		t.Fatalf("expected kick to succeed, got %v", err)
+	}
+	if _, err := service.AdvanceStep(session.ID, bob.Token); err == nil {
+		t.Fatal("expected the kicked token to be invalid")
+	}
+
+	if _, err := service.HostControl(session.ID, session.HostToken, HostCommand{Action: HostLock}); err != nil {
+		t.Fatalf("expected lock to succeed, got %v", err)
+	}
+	if _, err := service.JoinSession(session.ID, qubit.RoleBob, ""); err == nil || err.Error() != "session locked" {
+		t.Fatalf("expected a locked session to refuse joins, got %v", err)
+	}
+	if _, err := service.HostControl(session.ID, session.HostToken, HostCommand{Action: HostUnlock}); err != nil {
+		t.Fatalf("expected unlock to succeed, got %v", err)
+	}
+	if _, err := service.JoinSession(session.ID, qubit.RoleBob, ""); err != nil {
+		t.Fatalf("expected joining after unlock, got %v", err)
+	}
+}
+
+func TestHostRewindRestoresSnapshotBeforeMeasurement(t *testing.T) {
+	service := NewTeleportationService(WithSeed(3))
+	session, _ := service.CreateSession()
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+	bob, _ := service.JoinSession(session.ID, qubit.RoleBob, "")
+	if _, err := service.AdvanceStep(session.ID, alice.Token); err != nil {
+		t.Fatalf("expected advance to succeed, got %v", err)
+	}
+	beforeMeasure := append([]qubit.Qubit(nil), session.Qubits...)
+	logLength := len(session.Log)
+	for _, token := range []string{alice.Token, alice.Token, bob.Token, bob.Token} {
+		if token == bob.Token && session.StepIndex == 4 {
+			if _, err := service.ApplyCorrection(session.ID, token, session.Measurement.Correction); err != nil {
+				t.Fatalf("expected correction to succeed, got %v", err)
+			}
+		}
+		if _, err := service.AdvanceStep(session.ID, token); err != nil {
+			t.Fatalf("expected advance to succeed, got %v", err)
+		}
+	}
+	measured := *session.Measurement
+
+	rewound, err := service.HostControl(session.ID, session.HostToken, HostCommand{Action: HostRewind, Step: 3})
+	if err != nil {
+		t.Fatalf("expected rewind to the send step, got %v", err)
+	}
+	if rewound.Measurement == nil || *rewound.Measurement != measured || rewound.Correction != nil {
+		t.Fatalf("expected the recorded measurement without a correction, got %+v, %+v", rewound.Measurement, rewound.Correction)
+	}
+
+	rewound, err = service.HostControl(session.ID, session.HostToken, HostCommand{Action: HostRewind, Step: 1})
+	if err != nil {
+		t.Fatalf("expected rewind before the measurement, got %v", err)
+	}
+	if rewound.Measurement != nil || len(rewound.Log) != logLength+1 {
+		t.Fatalf("expected the log and results from before the measurement, got %+v, %d lines", rewound.Measurement, len(rewound.Log))
+	}
+	for i, qb := range rewound.Qubits {
+		if qb.State != beforeMeasure[i].State || math.Abs(qb.Bloch.Radius-beforeMeasure[i].Bloch.Radius) > 1e-9 {
+			t.Fatalf("expected qubit %d restored to %+v, got %+v", i, beforeMeasure[i], qb)
+		}
+	}
+	if len(rewound.History) != 2 {
+		t.Fatalf("expected snapshots after the restored step to be dropped, got %d", len(rewound.History))
+	}
+	if _, err := service.AdvanceStep(session.ID, alice.Token); err != nil || session.StepIndex != 2 || session.Measurement == nil {
+		t.Fatalf("expected alice to measure again after rewinding, got %v", err)
+	}
+}
+
+func TestHostRewindRestoresProtocolState(t *testing.T) {
+	service := NewTeleportationService(WithSeed(6))
+	session, _ := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolSecretSharing})
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+	for i := 0; i < 2; i++ {
+		if _, err := service.AdvanceStep(session.ID, alice.Token); err != nil {
+			t.Fatalf("expected advance to succeed, got %v", err)
+		}
+	}
+	measured := *session.Sharing.Clone()
+	if measured.Valid == nil {
+		t.Fatalf("expected the bases announced, got %+v", measured)
+	}
+
+	rewound, err := service.HostControl(session.ID, session.HostToken, HostCommand{Action: HostRewind, Step: 1})
+	if err != nil {
+		t.Fatalf("expected rewind to the measure step, got %v", err)
+	}
+	if s := rewound.Sharing; s == nil || s.Valid != nil || s.AliceBits != measured.AliceBits || s.BobBases != measured.BobBases {
+		t.Fatalf("expected the shares without the announced bases, got %+v", s)
+	}
+	// The snapshot must not share the record the session goes on to change.
+	rewound.Sharing.AliceBits = ""
+	_, _ = service.AdvanceStep(session.ID, alice.Token)
+	if _, err := service.HostControl(session.ID, session.HostToken, HostCommand{Action: HostRewind, Step: 1}); err != nil {
+		t.Fatalf("expected a second rewind, got %v", err)
+	}
+	if session.Sharing.AliceBits != measured.AliceBits {
+		t.Fatalf("expected the snapshot to keep its own copy, got %+v", session.Sharing)
+	}
+
+	if _, err := service.HostControl(session.ID, session.HostToken, HostCommand{Action: HostRegenerate}); err != nil {
+		t.Fatalf("expected regenerate to succeed, got %v", err)
+	}
+	if session.Sharing == nil || session.Sharing.AliceBits != "" || session.StepIndex != 0 {
+		t.Fatalf("expected a restart to clear the shares, got %+v", session.Sharing)
+	}
+}
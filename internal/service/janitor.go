@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// Reap releases roles whose owner has been disconnected for longer than the
+// role TTL, forgets observers that joined longer than the role TTL ago and have
+// no socket open, and deletes sessions idle for longer than the idle timeout
+// unless a participant or the host still has a socket open, closing any
+// observer sockets still attached to them. It returns how many roles and
+// sessions went away; a session the store fails to save or delete is left as
+// it was for the next pass and its error is returned.
+func (s *TeleportationService) Reap(now time.Time) (released int, deleted int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var errs []error
+	for _, session := range s.sessions.List() {
+		if s.idle > 0 && now.Sub(session.UpdatedAt) > s.idle && !s.attendedLocked(session.ID) {
+			if err := s.sessions.Delete(session.ID); err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			for conn := range s.listeners[session.ID] {
+				_ = conn.Close()
+			}
+			delete(s.listeners, session.ID)
+			deleted++
+			continue
+		}
+
+		var roles []qubit.Role
+		for role, p := range session.Participants {
+			if p.Token == "" || p.Connected || now.Sub(p.LastSeen) <= s.ttl {
+				continue
+			}
+			s.releaseRoleLocked(session, role)
+			session.Record(teleportation.Event{
+				Type:    teleportation.EventRoleLeft,
+				At:      now,
+				Payload: teleportation.EventPayload{Step: session.StepIndex, Role: role, Reason: teleportation.LeaveTimeout},
+			})
+			roles = append(roles, role)
+		}
+		changed := len(roles) > 0
+		for token, o := range session.Observers {
+			if now.Sub(o.JoinedAt) <= s.ttl || s.listeningLocked(session.ID, token) {
+				continue
+			}
+			s.removeObserverLocked(session, token)
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		// Saved without stamping UpdatedAt: a timeout is not activity.
+		if err := s.sessions.Save(session); err != nil {
+			s.reloadLocked(session.ID)
+			errs = append(errs, err)
+			continue
+		}
+		for _, role := range roles {
+			s.infoLocked(session, InfoEvent{Event: InfoRoleReleased, Role: role}, nil)
+		}
+		released += len(roles)
+		s.broadcastLocked(session)
+	}
+	return released, deleted, errors.Join(errs...)
+}
+
+// attendedLocked reports whether a participant or the host has a socket open
+// on the session. Such a session is only quiet, not abandoned; observers do
+// not keep one alive.
+func (s *TeleportationService) attendedLocked(id string) bool {
+	for _, entry := range s.listeners[id] {
+		if entry.role != qubit.RoleObserver {
+			return true
+		}
+	}
+	return false
+}
+
+// listeningLocked reports whether a socket is open with the token.
+func (s *TeleportationService) listeningLocked(id string, token string) bool {
+	for _, entry := range s.listeners[id] {
+		if entry.token == token {
+			return true
+		}
+	}
+	return false
+}
+
+// RunJanitor reaps sessions every interval until ctx is cancelled, passing
+// any error a pass returns to report.
+func (s *TeleportationService) RunJanitor(ctx context.Context, interval time.Duration, report func(error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			if _, _, err := s.Reap(now); err != nil {
+				report(err)
+			}
+		}
+	}
+}
+
+// Shutdown closes every WebSocket listener so their read loops exit.
+func (s *TeleportationService) Shutdown() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id, conns := range s.listeners {
+		for conn := range conns {
+			_ = conn.Close()
+		}
+		delete(s.listeners, id)
+	}
+}
@@ -0,0 +1,137 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+func TestReapReleasesStaleRolesAndDeletesIdleSessions(t *testing.T) {
+	service := NewTeleportationService(WithRoleTTL(time.Minute), WithIdleTimeout(time.Hour))
+	active, _ := service.CreateSession()
+	alice, _ := service.JoinSession(active.ID, qubit.RoleAlice, "")
+	abandoned, _ := service.CreateSession()
+
+	now := time.Now()
+	if released, deleted, _ := service.Reap(now); released != 0 || deleted != 0 {
+		t.Fatalf("expected fresh sessions to be kept, got released=%d deleted=%d", released, deleted)
+	}
+
+	stored, _ := service.sessions.Get(abandoned.ID)
+	stored.UpdatedAt = now.Add(-2 * time.Hour)
+
+	released, deleted, _ := service.Reap(now.Add(2 * time.Minute))
+	if released != 1 || deleted != 1 {
+		t.Fatalf("expected one role released and one session deleted, got released=%d deleted=%d", released, deleted)
+	}
+	if _, err := service.GetSession(abandoned.ID); err == nil {
+		t.Fatal("expected idle session to be deleted")
+	}
+
+	kept, err := service.GetSession(active.ID)
+	if err != nil {
+		t.Fatalf("expected active session to be kept, got %v", err)
+	}
+	if kept.Participants[qubit.RoleAlice].Taken {
+		t.Fatal("expected alice's stale role to be released")
+	}
+	if !strings.Contains(kept.Log[len(kept.Log)-1], "по таймауту") {
+		t.Fatalf("expected timeout release to be logged, log: %v", kept.Log)
+	}
+	if _, err := service.AdvanceStep(active.ID, alice.Token); err == nil {
+		t.Fatal("expected released token to be rejected")
+	}
+}
+
+func TestReapForgetsObserversWithoutKeepingSessionsAlive(t *testing.T) {
+	service := NewTeleportationService(WithRoleTTL(time.Minute), WithIdleTimeout(time.Hour))
+	watched, _ := service.CreateSession()
+	observer, _ := service.ObserveSession(watched.ID)
+
+	now := time.Now()
+	if _, deleted, _ := service.Reap(now); deleted != 0 || watched.ObserverCount != 1 {
+		t.Fatalf("expected a fresh observer to be kept, got %d observers", watched.ObserverCount)
+	}
+	service.Reap(now.Add(2 * time.Minute))
+	if watched.ObserverCount != 0 {
+		t.Fatalf("expected the stale observer to be forgotten, got %d observers", watched.ObserverCount)
+	}
+	if _, err := service.SessionView(watched.ID, observer.Token); err == nil {
+		t.Fatal("expected the forgotten observer token to be rejected")
+	}
+
+	idle, _ := service.CreateSession()
+	stored, _ := service.sessions.Get(idle.ID)
+	stored.UpdatedAt = now.Add(-2 * time.Hour)
+	for i := 0; i < 3; i++ {
+		_, _ = service.ObserveSession(idle.ID)
+	}
+	if _, deleted, _ := service.Reap(now); deleted != 1 {
+		t.Fatal("expected observers joining not to keep an idle session alive")
+	}
+}
+
+// flakyStore fails saves on demand the way FileStore fails a write.
+type flakyStore struct {
+	*FileStore
+	fail bool
+}
+
+func (f *flakyStore) Save(session *teleportation.SessionState) error {
+	if f.fail {
+		f.rollback(session)
+		return errors.New("disk full")
+	}
+	return f.FileStore.Save(session)
+}
+
+func TestReapLeavesSessionsItCannotSave(t *testing.T) {
+	files, err := NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("expected store to open, got %v", err)
+	}
+	store := &flakyStore{FileStore: files}
+	service := NewTeleportationService(WithStore(store), WithRoleTTL(time.Minute))
+	session, _ := service.CreateSession()
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+
+	store.fail = true
+	later := time.Now().Add(2 * time.Minute)
+	if released, _, err := service.Reap(later); err == nil || released != 0 {
+		t.Fatalf("expected the failed save to be reported and nothing released, got %d, %v", released, err)
+	}
+	kept, _ := service.GetSession(session.ID)
+	if !kept.Participants[qubit.RoleAlice].Taken {
+		t.Fatal("expected alice's role to stay taken in memory when it could not be saved")
+	}
+
+	store.fail = false
+	if released, _, err := service.Reap(later); err != nil || released != 1 {
+		t.Fatalf("expected the next pass to release the role, got %d, %v", released, err)
+	}
+	if _, err := service.AdvanceStep(session.ID, alice.Token); err == nil {
+		t.Fatal("expected released token to be rejected")
+	}
+}
+
+func TestReapKeepsIdleSessionsWithOpenSockets(t *testing.T) {
+	service := NewTeleportationService(WithIdleTimeout(time.Hour))
+	session, _ := service.CreateSession()
+	stored, _ := service.sessions.Get(session.ID)
+	stored.UpdatedAt = time.Now().Add(-2 * time.Hour)
+
+	service.listeners[session.ID] = map[*websocket.Conn]*listener{{}: {role: qubit.RoleAlice}}
+	if _, deleted, _ := service.Reap(time.Now()); deleted != 0 {
+		t.Fatal("expected a session with a participant connected to survive a quiet spell")
+	}
+	service.listeners[session.ID] = map[*websocket.Conn]*listener{}
+	if _, deleted, _ := service.Reap(time.Now()); deleted != 1 {
+		t.Fatal("expected the session to go once nobody is connected")
+	}
+}
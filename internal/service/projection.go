@@ -0,0 +1,80 @@
+package service
+
+import (
+	"errors"
+
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// projectLocked returns a copy of the session limited to what role may know:
+// the instructor sees everything, participants their own qubits and what the
+// protocol lets them hold, and any other role the observer's public view.
+func projectLocked(p Protocol, session *teleportation.SessionState, role qubit.Role) *teleportation.SessionState {
+	view := *session
+	view.Qubits = append([]qubit.Qubit(nil), session.Qubits...)
+	view.Log = append([]string(nil), session.Log...)
+	view.Participants = make(map[qubit.Role]teleportation.Participant, len(session.Participants))
+	for r, p := range session.Participants {
+		view.Participants[r] = p
+	}
+	// Protocols change their state in place, and views are encoded after the
+	// lock is released, so every view gets its own copy.
+	view.ProtocolState = session.CloneProtocolState()
+	// Every view names the chain hop the current step works on.
+	view.ActiveHop = session.CurrentStep().Hop
+	if role == qubit.RoleInstructor {
+		return &view
+	}
+
+	view.Config.InitialState = nil
+	view.Config.Seed = nil
+	for i, qb := range view.Qubits {
+		if qb.Role != role {
+			view.Qubits[i].Bloch = qubit.BlochState{}
+			view.Qubits[i].Hidden = true
+		}
+	}
+	if !measurementVisible(p, session, role) {
+		view.Measurement = nil
+	}
+	maskInterception(session, &view, role)
+	// Alice's superdense encoding stays hers until the run completes.
+	if role != qubit.RoleAlice && !stepReached(session, teleportation.StepComplete) {
+		view.Encoding = nil
+	}
+	view.KeyExchange = keyExchangeView(&view, role)
+	view.Sharing = sharingView(&view, role)
+	return &view
+}
+
+// measurementVisible reports whether role already holds the measured bits.
+// The instructor always does; a session whose protocol is no longer
+// registered shows them to nobody else.
+func measurementVisible(p Protocol, session *teleportation.SessionState, role qubit.Role) bool {
+	if role == qubit.RoleInstructor {
+		return true
+	}
+	return p != nil && p.MeasurementVisible(session, role)
+}
+
+// SessionView returns the session as seen by the holder of token. An empty
+// token yields the public view also given to observers.
+func (s *TeleportationService) SessionView(id string, token string) (*teleportation.SessionState, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions.Get(id)
+	if !ok {
+		return nil, errors.New("session not found")
+	}
+	role := qubit.RoleObserver
+	if token != "" {
+		r, err := s.listenerRoleLocked(session, token)
+		if err != nil {
+			return nil, err
+		}
+		role = r
+	}
+	return projectLocked(s.protocols[session.Config.Protocol], session, role), nil
+}
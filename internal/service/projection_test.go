@@ -0,0 +1,60 @@
+package service
+
+import (
+	"testing"
+
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+func TestSessionViewHidesForeignStateUntilSent(t *testing.T) {
+	service := NewTeleportationService()
+	preset, _ := qubit.Preset("+")
+	session, _ := service.CreateSessionWithConfig(teleportation.Config{InitialState: &preset})
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+	bob, _ := service.JoinSession(session.ID, qubit.RoleBob, "")
+	for _, token := range []string{alice.Token, alice.Token} {
+		if _, err := service.AdvanceStep(session.ID, token); err != nil {
+			t.Fatalf("expected advance to succeed, got %v", err)
+		}
+	}
+
+	bobView, err := service.SessionView(session.ID, bob.Token)
+	if err != nil {
+		t.Fatalf("expected bob's view, got %v", err)
+	}
+	if !bobView.Qubits[0].Hidden || bobView.Qubits[0].Bloch != (qubit.BlochState{}) {
+		t.Fatalf("expected alice's qubit to be hidden from bob, got %+v", bobView.Qubits[0])
+	}
+	if bobView.Qubits[1].Hidden {
+		t.Fatal("expected bob to see his own qubit")
+	}
+	if bobView.Measurement != nil {
+		t.Fatal("expected bob not to see the bits before they are sent")
+	}
+	if bobView.Config.InitialState != nil || bobView.Config.Seed != nil {
+		t.Fatalf("expected prepared state and seed to be withheld, got %+v", bobView.Config)
+	}
+
+	aliceView, _ := service.SessionView(session.ID, alice.Token)
+	if aliceView.Measurement == nil {
+		t.Fatal("expected alice to see her own measurement")
+	}
+
+	if _, err := service.AdvanceStep(session.ID, alice.Token); err != nil {
+		t.Fatalf("expected alice to send the bits, got %v", err)
+	}
+	bobView, _ = service.SessionView(session.ID, bob.Token)
+	if bobView.Measurement == nil {
+		t.Fatal("expected bob to see the bits after the classical send")
+	}
+
+	stored, _ := service.sessions.Get(session.ID)
+	full := projectLocked(teleportationProtocol{}, stored, qubit.RoleInstructor)
+	if full.Qubits[0].Hidden || full.Config.InitialState == nil {
+		t.Fatalf("expected instructor to get the full view, got %+v", full)
+	}
+	if stored.Qubits[0].Hidden || stored.Measurement == nil {
+		t.Fatal("expected projections not to mutate the stored session")
+	}
+}
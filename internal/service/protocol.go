@@ -0,0 +1,177 @@
+package service
+
+import (
+	"errors"
+
+	"quantum-teleport/internal/domain/quantum"
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+	"quantum-teleport/pkg/utils"
+)
+
+// Protocol describes a quantum communication protocol a session can run: its
+// steps, the roles taking part, who may act on which step and what happens to
+// the simulated register when a step is entered. Implementations keep no state
+// of their own; everything a run needs lives on the session, what only some
+// protocols track in its ProtocolState.
+type Protocol interface {
+	// Name is the identifier sessions select the protocol by in their config.
+	Name() string
+	// Steps lists the steps of a session created with config, in order.
+	Steps(config teleportation.Config) []teleportation.StepInfo
+	// Roles lists the participant roles a session created with config offers.
+	Roles(config teleportation.Config) []qubit.Role
+	// Allowed reports whether role may act on, and advance from, step.
+	Allowed(step teleportation.StepInfo, role qubit.Role) bool
+	// Prepare sets up the first step from the session config and its seeded
+	// stream: the hidden state, the register and the displayed qubits.
+	Prepare(session *teleportation.SessionState)
+	// Ready reports why the session may not leave its current step yet.
+	Ready(session *teleportation.SessionState) error
+	// Enter applies the physics of the step the session has just entered.
+	Enter(session *teleportation.SessionState)
+	// Sync refreshes the displayed qubits from the register.
+	Sync(session *teleportation.SessionState)
+	// MeasurementVisible reports whether a participant role already holds the
+	// classical bits of the session's measurement.
+	MeasurementVisible(session *teleportation.SessionState, role qubit.Role) bool
+}
+
+// corrector is implemented by protocols whose reconstruct step takes a Pauli
+// correction from Bob. Correct applies it, undoing a previous choice, and
+// scores the result.
+type corrector interface {
+	Correct(session *teleportation.SessionState, correction teleportation.Correction) teleportation.CorrectionAttempt
+}
+
+// configValidator is implemented by protocols with options of their own;
+// Validate rejects a config the protocol cannot run.
+type configValidator interface {
+	Validate(config teleportation.Config) error
+}
+
+// noiseless is implemented by protocols with steps that add no round of
+// channel noise.
+type noiseless interface {
+	Noiseless(step teleportation.StepInfo) bool
+}
+
+// replayer is implemented by protocols that record events of their own.
+// Replay re-applies or checks such an event while a session is rebuilt from
+// its stream, and reports false for an event type it does not know.
+type replayer interface {
+	Replay(session *teleportation.SessionState, e teleportation.Event) (bool, error)
+}
+
+// WithProtocol registers an additional protocol, or replaces the one with the same name.
+func WithProtocol(p Protocol) Option {
+	return func(s *TeleportationService) {
+		s.protocols[p.Name()] = p
+	}
+}
+
+// protocolFor resolves the protocol a session was created with.
+func (s *TeleportationService) protocolFor(session *teleportation.SessionState) (Protocol, error) {
+	p, ok := s.protocols[session.Config.Protocol]
+	if !ok {
+		return nil, errors.New("unsupported protocol")
+	}
+	return p, nil
+}
+
+// validateConfig checks the protocol's own options, if it has any.
+func validateConfig(p Protocol, config teleportation.Config) error {
+	if v, ok := p.(configValidator); ok {
+		return v.Validate(config)
+	}
+	return nil
+}
+
+// rejectInitialState refuses a configured initial state for a protocol that
+// transfers no unknown state.
+func rejectInitialState(config teleportation.Config) error {
+	if config.InitialState != nil {
+		return errors.New("initial state not supported")
+	}
+	return nil
+}
+
+// configOption names an optional Config field a protocol may use.
+type configOption int
+
+const (
+	optionRelays configOption = 1 << iota
+	optionQubits
+	optionEavesdropper
+)
+
+// rejectUnusedOptions refuses the optional fields set in config that a
+// protocol does not use; uses lists the ones it does.
+func rejectUnusedOptions(config teleportation.Config, uses configOption) error {
+	switch {
+	case config.Relays != 0 && uses&optionRelays == 0:
+		return errors.New("relays not supported")
+	case config.Qubits != 0 && uses&optionQubits == 0:
+		return errors.New("qubit count not supported")
+	case config.Eavesdropper && uses&optionEavesdropper == 0:
+		return errors.New("eavesdropper not supported")
+	}
+	return nil
+}
+
+// newSessionLocked builds the first step of a session running p. The caller
+// sets the ID, host token and timestamps.
+func newSessionLocked(p Protocol, config teleportation.Config, random *utils.SeededRand) *teleportation.SessionState {
+	roles := p.Roles(config)
+	participants := make(map[qubit.Role]teleportation.Participant, len(roles))
+	for _, role := range roles {
+		participants[role] = teleportation.Participant{Role: role}
+	}
+	session := &teleportation.SessionState{
+		Config:       config,
+		Steps:        p.Steps(config),
+		Participants: participants,
+		Random:       random,
+	}
+	p.Prepare(session)
+	p.Sync(session)
+	return session
+}
+
+// blankRegister returns n qubits in |0...0>: a state vector, or a density
+// matrix when the session is noisy.
+func blankRegister(config teleportation.Config, n int) quantum.Register {
+	if config.Noise != nil {
+		return quantum.NewDensityMatrix(n)
+	}
+	return quantum.NewStateVector(n)
+}
+
+// correctPauliLocked applies Bob's correction to qubit q, first undoing his
+// previous choice, and records the attempt with the fidelity score reports.
+// The attempt is correct when it matches expected, the correction the
+// announced bits dictate.
+func correctPauliLocked(session *teleportation.SessionState, q int, correction, expected teleportation.Correction, score func() float64) teleportation.CorrectionAttempt {
+	attempt := teleportation.CorrectionAttempt{Applied: correction, Attempts: 1}
+	if previous := session.Correction; previous != nil {
+		undoPauli(session.Register, q, previous.Applied)
+		attempt.Attempts = previous.Attempts + 1
+	}
+	applyPauli(session.Register, q, correction)
+
+	attempt.Fidelity = score()
+	attempt.Correct = correction == expected
+	session.Correction = &attempt
+	return attempt
+}
+
+// enterStepLocked runs the protocol transition into the current step, then the
+// session's noise channel unless the step is noiseless, and refreshes the
+// displayed qubits.
+func enterStepLocked(p Protocol, session *teleportation.SessionState) {
+	p.Enter(session)
+	if n, ok := p.(noiseless); !ok || !n.Noiseless(session.CurrentStep()) {
+		applyNoiseLocked(session)
+	}
+	p.Sync(session)
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"quantum-teleport/internal/domain/quantum"
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// flipProtocol is a one-qubit protocol: Bob flips Alice's qubit once she armed it.
+type flipProtocol struct{}
+
+func (flipProtocol) Name() string { return "flip" }
+
+func (flipProtocol) Steps(teleportation.Config) []teleportation.StepInfo {
+	return []teleportation.StepInfo{
+		{Key: "arm", Title: "Подготовка"},
+		{Key: "flip", Title: "Переворот"},
+		{Key: "done", Title: "Готово"},
+	}
+}
+
+func (flipProtocol) Roles(teleportation.Config) []qubit.Role {
+	return []qubit.Role{qubit.RoleAlice, qubit.RoleBob}
+}
+
+func (flipProtocol) Allowed(step teleportation.StepInfo, role qubit.Role) bool {
+	return (step.Key == "arm" && role == qubit.RoleAlice) || (step.Key == "flip" && role == qubit.RoleBob)
+}
+
+func (flipProtocol) Prepare(session *teleportation.SessionState) {
+	session.Register = quantum.NewStateVector(1)
+	session.Qubits = []qubit.Qubit{{ID: "q1", Role: qubit.RoleAlice}}
+}
+
+func (flipProtocol) Ready(*teleportation.SessionState) error { return nil }
+
+func (flipProtocol) Enter(session *teleportation.SessionState) {
+	if session.CurrentStep().Key == "done" {
+		session.Register.Apply(quantum.X, 0)
+	}
+}
+
+func (flipProtocol) Sync(session *teleportation.SessionState) {
+	session.Qubits[0].Bloch = session.Register.Bloch(0)
+}
+
+func (flipProtocol) MeasurementVisible(*teleportation.SessionState, qubit.Role) bool { return false }
+
+func TestSessionsRunTheirChosenProtocol(t *testing.T) {
+	service := NewTeleportationService(WithProtocol(flipProtocol{}))
+	session, err := service.CreateSessionWithConfig(teleportation.Config{Protocol: "flip"})
+	if err != nil {
+		t.Fatalf("expected a registered protocol to be accepted, got %v", err)
+	}
+	if len(session.Steps) != 3 || session.Steps[1].Key != "flip" {
+		t.Fatalf("expected the protocol steps, got %+v", session.Steps)
+	}
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+	bob, _ := service.JoinSession(session.ID, qubit.RoleBob, "")
+
+	if _, err := service.AdvanceStep(session.ID, bob.Token); err == nil {
+		t.Fatal("expected the protocol to keep bob from arming")
+	}
+	if _, err := service.AdvanceStep(session.ID, alice.Token); err != nil {
+		t.Fatalf("expected alice to arm, got %v", err)
+	}
+	if _, err := service.AdvanceStep(session.ID, bob.Token); err != nil {
+		t.Fatalf("expected bob to flip, got %v", err)
+	}
+	if theta := session.Qubits[0].Bloch.Theta; math.Abs(theta-math.Pi) > 1e-9 {
+		t.Fatalf("expected the transition to flip the qubit to |1>, got theta %.3f", theta)
+	}
+	if _, err := service.ApplyCorrection(session.ID, bob.Token, teleportation.CorrectionX); err == nil {
+		t.Fatal("expected teleportation corrections to be refused outside teleportation")
+	}
+
+	rebuilt, err := service.Rebuild(session.Events)
+	if err != nil || rebuilt.StepIndex != 2 || rebuilt.Qubits[0].Bloch != session.Qubits[0].Bloch {
+		t.Fatalf("expected the protocol run to replay, got %+v, %v", rebuilt, err)
+	}
+}
+
+func TestProtocolsRefuseOptionsTheyDoNotUse(t *testing.T) {
+	service := NewTeleportationService()
+	cases := []struct {
+		config teleportation.Config
+		err    string
+	}{
+		{teleportation.Config{Protocol: teleportation.ProtocolTeleportation, Relays: 3}, "relays not supported"},
+		{teleportation.Config{Protocol: teleportation.ProtocolTeleportation, Qubits: 8}, "qubit count not supported"},
+		{teleportation.Config{Protocol: teleportation.ProtocolSuperdense, Eavesdropper: true}, "eavesdropper not supported"},
+		{teleportation.Config{Protocol: teleportation.ProtocolSwapping, Relays: 1}, "relays not supported"},
+		{teleportation.Config{Protocol: teleportation.ProtocolChain, Relays: 2, Eavesdropper: true}, "eavesdropper not supported"},
+		{teleportation.Config{Protocol: teleportation.ProtocolChain, Relays: 2, Qubits: 4}, "qubit count not supported"},
+		{teleportation.Config{Protocol: teleportation.ProtocolBB84, Relays: 2}, "relays not supported"},
+		{teleportation.Config{Protocol: teleportation.ProtocolControlled, Qubits: 8}, "qubit count not supported"},
+		{teleportation.Config{Protocol: teleportation.ProtocolSecretSharing, Eavesdropper: true}, "eavesdropper not supported"},
+	}
+	for _, c := range cases {
+		if _, err := service.CreateSessionWithConfig(c.config); err == nil || err.Error() != c.err {
+			t.Fatalf("expected %+v to be refused with %q, got %v", c.config, c.err, err)
+		}
+	}
+	for _, config := range []teleportation.Config{
+		{Protocol: teleportation.ProtocolTeleportation, Eavesdropper: true},
+		{Protocol: teleportation.ProtocolChain, Relays: 2},
+		{Protocol: teleportation.ProtocolBB84, Qubits: 8, Eavesdropper: true},
+		{Protocol: teleportation.ProtocolSecretSharing, Qubits: 8},
+	} {
+		if _, err := service.CreateSessionWithConfig(config); err != nil {
+			t.Fatalf("expected %+v to be accepted, got %v", config, err)
+		}
+	}
+}
+
+func TestCreateSessionRejectsUnregisteredProtocol(t *testing.T) {
+	service := NewTeleportationService()
+	if _, err := service.CreateSessionWithConfig(teleportation.Config{Protocol: "flip"}); err == nil {
+		t.Fatal("expected an unregistered protocol to be rejected")
+	}
+}
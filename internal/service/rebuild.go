@@ -0,0 +1,123 @@
+package service
+
+import (
+	"errors"
+
+	"quantum-teleport/internal/domain/teleportation"
+	"quantum-teleport/pkg/utils"
+)
+
+// Rebuild replays an event stream onto a fresh session and returns the
+// resulting state, detached from the store and without participant tokens.
+// The stream starts with session_created, whose config seed draws the unknown
+// state and every measurement again; a recorded measurement the seed does not
+// reproduce is an error.
+func (s *TeleportationService) Rebuild(events []teleportation.Event) (*teleportation.SessionState, error) {
+	return s.rebuild(events, nil)
+}
+
+// rebuild replays events and calls visit with the session after each of them,
+// its Events and Log covering the events applied so far.
+func (s *TeleportationService) rebuild(events []teleportation.Event, visit func(*teleportation.SessionState, teleportation.Event)) (*teleportation.SessionState, error) {
+	if len(events) == 0 || events[0].Type != teleportation.EventSessionCreated || events[0].Payload.Config == nil {
+		return nil, errors.New("events must start with session_created")
+	}
+	config := *events[0].Payload.Config
+	if config.Seed == nil {
+		return nil, errors.New("seed required")
+	}
+
+	protocol, ok := s.protocols[config.Protocol]
+	if !ok {
+		return nil, errors.New("unsupported protocol")
+	}
+	if err := validateConfig(protocol, config); err != nil {
+		return nil, err
+	}
+	session := newSessionLocked(protocol, config, utils.NewSeededRand(*config.Seed))
+
+	for i, e := range events {
+		if err := replayEventLocked(protocol, session, e); err != nil {
+			return nil, errors.New("event " + string(e.Type) + ": " + err.Error())
+		}
+		session.Events = append([]teleportation.Event(nil), events[:i+1]...)
+		session.Log = teleportation.RenderLog(session.Events)
+		session.UpdatedAt = e.At
+		if visit != nil {
+			visit(session, e)
+		}
+	}
+	return session, nil
+}
+
+// replayEventLocked applies one recorded event the way the live service did.
+// Measurement and bit events follow from the step transition and are only
+// checked; events of a single protocol are left to its Replay.
+func replayEventLocked(protocol Protocol, session *teleportation.SessionState, e teleportation.Event) error {
+	p := e.Payload
+	switch e.Type {
+	case teleportation.EventSessionCreated:
+		if p.State != nil && *p.State != session.HiddenState {
+			return errors.New("unknown state does not match the seed")
+		}
+		session.Checkpoint()
+	case teleportation.EventRoleJoined, teleportation.EventRoleLeft:
+		participant, ok := session.Participants[p.Role]
+		if !ok {
+			return errors.New("role unsupported")
+		}
+		participant.Taken = e.Type == teleportation.EventRoleJoined
+		session.Participants[p.Role] = participant
+	case teleportation.EventStepAdvanced:
+		if p.Step != session.StepIndex+1 || p.Step >= len(session.Steps) {
+			return errors.New("step out of order")
+		}
+		if err := protocol.Ready(session); err != nil {
+			return err
+		}
+		session.StepIndex++
+		enterStepLocked(protocol, session)
+		session.Checkpoint()
+	case teleportation.EventMeasurementTaken:
+		if session.Measurement == nil || p.Measurement == nil || *session.Measurement != *p.Measurement {
+			return errors.New("measurement does not match the seed")
+		}
+	case teleportation.EventBitsSent:
+	case teleportation.EventCorrectionApplied:
+		fixer, ok := protocol.(corrector)
+		if !ok || p.Correction == nil || !p.Correction.Applied.Valid() || session.CurrentStep().Key != teleportation.StepReconstruct {
+			return errors.New("invalid correction")
+		}
+		fixer.Correct(session, p.Correction.Applied)
+	case teleportation.EventHostAction:
+		if err := hostActionLocked(protocol, session, HostCommand{Action: HostAction(p.Action), Step: p.Step}); err != nil {
+			return err
+		}
+		if p.State != nil && *p.State != session.HiddenState {
+			return errors.New("unknown state does not match the seed")
+		}
+	default:
+		if r, ok := protocol.(replayer); ok {
+			if handled, err := r.Replay(session, e); handled {
+				return err
+			}
+		}
+		return errors.New("unknown event type")
+	}
+	return nil
+}
+
+// Events returns the typed history of a session to its host.
+func (s *TeleportationService) Events(id string, hostToken string) ([]teleportation.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	session, ok := s.sessions.Get(id)
+	if !ok {
+		return nil, errors.New("session not found")
+	}
+	if hostToken == "" || hostToken != session.HostToken {
+		return nil, errors.New("unknown host token")
+	}
+	return append([]teleportation.Event(nil), session.Events...), nil
+}
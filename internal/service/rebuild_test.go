@@ -0,0 +1,93 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+func TestEventsRebuildLogAndState(t *testing.T) {
+	service := NewTeleportationService(WithSeed(11))
+	session, _ := service.CreateSession()
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+	bob, _ := service.JoinSession(session.ID, qubit.RoleBob, "")
+	advance := func(tokens ...string) {
+		t.Helper()
+		for _, token := range tokens {
+			if _, err := service.AdvanceStep(session.ID, token); err != nil {
+				t.Fatalf("expected advance to succeed, got %v", err)
+			}
+		}
+	}
+	advance(alice.Token, alice.Token, alice.Token, bob.Token)
+	wrong := teleportation.CorrectionX
+	if session.Measurement.Correction == wrong {
+		wrong = teleportation.CorrectionZ
+	}
+	_, _ = service.ApplyCorrection(session.ID, bob.Token, wrong)
+	_, _ = service.ApplyCorrection(session.ID, bob.Token, session.Measurement.Correction)
+	advance(bob.Token)
+	if _, err := service.HostControl(session.ID, session.HostToken, HostCommand{Action: HostRewind, Step: 1}); err != nil {
+		t.Fatalf("expected rewind to succeed, got %v", err)
+	}
+	advance(alice.Token, alice.Token)
+	if _, err := service.LeaveSession(session.ID, bob.Token); err != nil {
+		t.Fatalf("expected leave to succeed, got %v", err)
+	}
+
+	events, err := service.Events(session.ID, session.HostToken)
+	if err != nil {
+		t.Fatalf("expected host to read events, got %v", err)
+	}
+	if _, err := service.Events(session.ID, alice.Token); err == nil {
+		t.Fatal("expected events to be host-only")
+	}
+	for i, e := range events {
+		if e.Seq != i+1 || e.At.IsZero() {
+			t.Fatalf("expected sequenced, timestamped events, got %+v", e)
+		}
+	}
+	if events[len(events)-1].Type != teleportation.EventRoleLeft || events[len(events)-1].Actor != qubit.RoleBob {
+		t.Fatalf("expected bob's leave to be the last event, got %+v", events[len(events)-1])
+	}
+	if !reflect.DeepEqual(teleportation.RenderLog(events), session.Log) {
+		t.Fatalf("expected the log to be rendered from events\nlog:    %v\nrender: %v", session.Log, teleportation.RenderLog(events))
+	}
+
+	rebuilt, err := service.Rebuild(events)
+	if err != nil {
+		t.Fatalf("expected rebuild to succeed, got %v", err)
+	}
+	if rebuilt.StepIndex != session.StepIndex || !reflect.DeepEqual(rebuilt.Measurement, session.Measurement) {
+		t.Fatalf("expected rebuilt step %d and measurement %+v, got %d and %+v", session.StepIndex, session.Measurement, rebuilt.StepIndex, rebuilt.Measurement)
+	}
+	if !reflect.DeepEqual(rebuilt.Qubits, session.Qubits) || !reflect.DeepEqual(rebuilt.Log, session.Log) {
+		t.Fatalf("expected rebuilt qubits and log to match\nlive:    %+v\nrebuilt: %+v", session.Qubits, rebuilt.Qubits)
+	}
+	if !rebuilt.Participants[qubit.RoleAlice].Taken || rebuilt.Participants[qubit.RoleBob].Taken {
+		t.Fatalf("expected rebuilt roles to follow joins and leaves, got %+v", rebuilt.Participants)
+	}
+
+	seed := *session.Config.Seed + 1
+	other := *events[0].Payload.Config
+	other.Seed = &seed
+	tampered := append([]teleportation.Event(nil), events...)
+	tampered[0].Payload.Config = &other
+	if _, err := service.Rebuild(tampered); err == nil {
+		t.Fatal("expected a different seed to contradict the recorded measurement")
+	}
+}
+
+func TestRebuildLeavesProtocolEventsToTheirProtocol(t *testing.T) {
+	service := NewTeleportationService(WithSeed(2))
+	session, _ := service.CreateSession()
+	events := []teleportation.Event{session.Events[0], {
+		Type:    teleportation.EventBitsEncoded,
+		Payload: teleportation.EventPayload{Encoding: &teleportation.Encoding{Bits: "10"}},
+	}}
+	if _, err := service.Rebuild(events); err == nil || err.Error() != "event bits_encoded: unknown event type" {
+		t.Fatalf("expected a superdense event to be unknown to teleportation, got %v", err)
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// ReplayFrame is one recorded state of a session, stamped with the time of
+// the event that produced it.
+type ReplayFrame struct {
+	At      time.Time
+	Message BroadcastMessage
+}
+
+// ReplayFrames rebuilds the recorded run of a session as the instructor saw it,
+// one state_update per transition. A measurement, the sending of the bits and
+// the BB84 key exchange events belong to the step change that caused them and
+// share its frame.
+func (s *TeleportationService) ReplayFrames(id string, hostToken string) ([]ReplayFrame, error) {
+	s.mu.RLock()
+	session, ok := s.sessions.Get(id)
+	if !ok {
+		s.mu.RUnlock()
+		return nil, errors.New("session not found")
+	}
+	if hostToken == "" || hostToken != session.HostToken {
+		s.mu.RUnlock()
+		return nil, errors.New("unknown host token")
+	}
+	events := append([]teleportation.Event(nil), session.Events...)
+	s.mu.RUnlock()
+
+	var frames []ReplayFrame
+	_, err := s.rebuild(events, func(state *teleportation.SessionState, e teleportation.Event) {
+		state.ID = id
+		view := projectLocked(nil, state, qubit.RoleInstructor)
+		frame := ReplayFrame{At: e.At, Message: BroadcastMessage{
+			Type:   "state_update",
+			Global: view,
+			Local:  LocalView{Role: qubit.RoleInstructor, Measurement: view.Measurement},
+		}}
+		if e.Type.FollowsStep() && len(frames) > 0 {
+			frames[len(frames)-1].Message = frame.Message
+			return
+		}
+		frames = append(frames, frame)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return frames, nil
+}
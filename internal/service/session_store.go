@@ -0,0 +1,63 @@
+package service
+
+import (
+	"sync"
+
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// SessionStore keeps sessions between requests. Implementations hand out live
+// pointers: the service mutates them under its own lock and saves them back.
+// A failed Save must not leave the change behind for the next Get.
+type SessionStore interface {
+	Get(id string) (*teleportation.SessionState, bool)
+	Save(session *teleportation.SessionState) error
+	Delete(id string) error
+	List() []*teleportation.SessionState
+}
+
+// MemoryStore keeps sessions in process memory only.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*teleportation.SessionState
+}
+
+// NewMemoryStore constructs an empty in-memory store.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string]*teleportation.SessionState)}
+}
+
+// Get returns the session with the given ID.
+func (m *MemoryStore) Get(id string) (*teleportation.SessionState, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	session, ok := m.sessions[id]
+	return session, ok
+}
+
+// Save stores the session under its ID.
+func (m *MemoryStore) Save(session *teleportation.SessionState) error {
+	m.mu.Lock()
+	m.sessions[session.ID] = session
+	m.mu.Unlock()
+	return nil
+}
+
+// Delete removes the session.
+func (m *MemoryStore) Delete(id string) error {
+	m.mu.Lock()
+	delete(m.sessions, id)
+	m.mu.Unlock()
+	return nil
+}
+
+// List returns all stored sessions.
+func (m *MemoryStore) List() []*teleportation.SessionState {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	sessions := make([]*teleportation.SessionState, 0, len(m.sessions))
+	for _, session := range m.sessions {
+		sessions = append(sessions, session)
+	}
+	return sessions
+}
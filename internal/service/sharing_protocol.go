@@ -0,0 +1,245 @@
+package service
+
+import (
+	"errors"
+
+	"quantum-teleport/internal/domain/quantum"
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// sharingProtocol splits Alice's key between Bob and Charlie: each round the
+// three measure their part of a GHZ triple in a random X or Y basis. In the
+// rounds with an even number of Y bases Bob's and Charlie's bits together
+// give Alice's, while either bit alone tells nothing about it.
+//
+// Like BB84 it keeps no joint state across rounds: every triple is built and
+// measured on a three-qubit scratch register.
+type sharingProtocol struct{}
+
+func (sharingProtocol) Name() string {
+	return teleportation.ProtocolSecretSharing
+}
+
+func (sharingProtocol) Validate(config teleportation.Config) error {
+	if config.Qubits < 0 || config.Qubits > teleportation.MaxSharingRounds {
+		return errors.New("invalid qubit count")
+	}
+	if err := rejectUnusedOptions(config, optionQubits); err != nil {
+		return err
+	}
+	return rejectInitialState(config)
+}
+
+// sharingRounds returns the number of GHZ triples a session shares.
+func sharingRounds(config teleportation.Config) int {
+	if config.Qubits == 0 {
+		return teleportation.DefaultSharingRounds
+	}
+	return config.Qubits
+}
+
+func (sharingProtocol) Steps(teleportation.Config) []teleportation.StepInfo {
+	return []teleportation.StepInfo{
+		{Key: teleportation.StepEntangle, Title: "Раздача GHZ-троек", Description: "Алиса, Боб и Чарли получают по кубиту каждой GHZ-тройки (|000⟩+|111⟩)/√2."},
+		{Key: teleportation.StepMeasure, Title: "Измерения в случайных базисах", Description: "Каждый участник измеряет свои кубиты в случайно выбранном базисе X или Y."},
+		{Key: teleportation.StepSift, Title: "Объявление базисов", Description: "Базисы объявляются открыто; остаются раунды с чётным числом базисов Y, где исходы связаны."},
+		{Key: teleportation.StepCombine, Title: "Объединение долей", Description: "Боб и Чарли вместе восстанавливают биты Алисы; поодиночке каждый угадывает лишь в половине случаев."},
+		{Key: teleportation.StepComplete, Title: "Готово", Description: "Ключ Алисы разделён: прочесть его могут только Боб и Чарли вместе."},
+	}
+}
+
+func (sharingProtocol) Roles(teleportation.Config) []qubit.Role {
+	return []qubit.Role{qubit.RoleAlice, qubit.RoleBob, qubit.RoleCharlie}
+}
+
+// Allowed lets any of the three move the shared steps on; only Bob and
+// Charlie decide to pool their shares.
+func (sharingProtocol) Allowed(step teleportation.StepInfo, role qubit.Role) bool {
+	switch step.Key {
+	case teleportation.StepEntangle, teleportation.StepMeasure, teleportation.StepSift:
+		return role == qubit.RoleAlice || role == qubit.RoleBob || role == qubit.RoleCharlie
+	case teleportation.StepCombine:
+		return role == qubit.RoleBob || role == qubit.RoleCharlie
+	default:
+		return false
+	}
+}
+
+// Prepare shows one qubit per party standing for its parts of all triples.
+func (sharingProtocol) Prepare(session *teleportation.SessionState) {
+	session.HiddenState = qubit.BlochState{}
+	session.Register = blankRegister(session.Config, 3)
+	session.Sharing = &teleportation.SecretSharing{
+		SharingReport: teleportation.SharingReport{Rounds: sharingRounds(session.Config)},
+	}
+	session.Qubits = []qubit.Qubit{
+		{ID: "q1", Role: qubit.RoleAlice, State: "Чистое состояние"},
+		{ID: "q2", Role: qubit.RoleBob, State: "Чистое состояние"},
+		{ID: "q3", Role: qubit.RoleCharlie, State: "Чистое состояние"},
+	}
+}
+
+func (sharingProtocol) Ready(*teleportation.SessionState) error {
+	return nil
+}
+
+func (sharingProtocol) Enter(session *teleportation.SessionState) {
+	s := session.Sharing
+	switch session.CurrentStep().Key {
+	case teleportation.StepMeasure:
+		s.AliceBases = randomSharingBases(session, s.Rounds)
+		s.BobBases = randomSharingBases(session, s.Rounds)
+		s.CharlieBases = randomSharingBases(session, s.Rounds)
+		s.AliceBits, s.BobBits, s.CharlieBits = measureTriples(session)
+		for q := range session.Qubits {
+			session.Qubits[q].State = "Измерены"
+		}
+		recordSharing(session, teleportation.EventSharesMeasured, "")
+	case teleportation.StepSift:
+		s.Valid = nil
+		for i := 0; i < s.Rounds; i++ {
+			y := 0
+			for _, bases := range []string{s.AliceBases, s.BobBases, s.CharlieBases} {
+				if bases[i] == teleportation.BasisY {
+					y++
+				}
+			}
+			if y%2 == 0 {
+				s.Valid = append(s.Valid, i)
+			}
+		}
+		s.ValidCount = len(s.Valid)
+		recordSharing(session, teleportation.EventSharesSifted, "")
+	case teleportation.StepCombine:
+		combineShares(s)
+		recordSharing(session, teleportation.EventSharesCombined, "")
+	}
+}
+
+// Sync shows the scratch register, which holds the last measured triple.
+func (sharingProtocol) Sync(session *teleportation.SessionState) {
+	for q := range session.Qubits {
+		session.Qubits[q].Bloch = session.Register.Bloch(q)
+	}
+}
+
+// MeasurementVisible is always false: the outcomes live in the sharing record.
+func (sharingProtocol) MeasurementVisible(*teleportation.SessionState, qubit.Role) bool {
+	return false
+}
+
+// Replay checks the recorded outcome of a step against the one the seed
+// reproduced on entering it.
+func (sharingProtocol) Replay(session *teleportation.SessionState, e teleportation.Event) (bool, error) {
+	switch e.Type {
+	case teleportation.EventSharesMeasured, teleportation.EventSharesSifted, teleportation.EventSharesCombined:
+		if session.Sharing == nil || e.Payload.Sharing == nil || *e.Payload.Sharing != session.Sharing.SharingReport {
+			return true, errors.New("secret sharing does not match the seed")
+		}
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// measureTriples builds a GHZ triple on the scratch register for every round,
+// exposes it to the session noise and measures each qubit in its party's basis.
+func measureTriples(session *teleportation.SessionState) (alice, bob, charlie string) {
+	s := session.Sharing
+	register := session.Register
+	bases := []string{s.AliceBases, s.BobBases, s.CharlieBases}
+	outcomes := [3][]byte{}
+	for q := range outcomes {
+		outcomes[q] = make([]byte, s.Rounds)
+	}
+	for i := 0; i < s.Rounds; i++ {
+		for q := range outcomes {
+			register.Reset(q)
+		}
+		register.Apply(quantum.H, 0)
+		register.CNOT(0, 1)
+		register.CNOT(0, 2)
+		applyNoiseLocked(session)
+		for q := range outcomes {
+			// S† then H measures in the Y basis, H alone in the X basis.
+			if bases[q][i] == teleportation.BasisY {
+				register.Apply(quantum.Sdg, q)
+			}
+			register.Apply(quantum.H, q)
+			outcomes[q][i] = byte('0' + register.Measure(q, session.Random.Float64()))
+		}
+	}
+	return string(outcomes[0]), string(outcomes[1]), string(outcomes[2])
+}
+
+// combineShares compares the bit Bob and Charlie reconstruct with Alice's in
+// every valid round and how often each of them alone would have guessed it.
+func combineShares(s *teleportation.SecretSharing) {
+	s.Errors = 0
+	bobAlone, charlieAlone := 0, 0
+	for _, i := range s.Valid {
+		if s.SharedBit(i) != s.AliceBits[i] {
+			s.Errors++
+		}
+		if s.BobBits[i] == s.AliceBits[i] {
+			bobAlone++
+		}
+		if s.CharlieBits[i] == s.AliceBits[i] {
+			charlieAlone++
+		}
+	}
+	s.BobAlone, s.CharlieAlone = 0, 0
+	if len(s.Valid) > 0 {
+		s.BobAlone = float64(bobAlone) / float64(len(s.Valid))
+		s.CharlieAlone = float64(charlieAlone) / float64(len(s.Valid))
+	}
+	s.KeyLength = len(s.Valid)
+}
+
+// recordSharing records a secret-sharing event with the public outcome reached so far.
+func recordSharing(session *teleportation.SessionState, typ teleportation.EventType, actor qubit.Role) {
+	report := session.Sharing.SharingReport
+	session.Record(teleportation.Event{
+		Type:    typ,
+		Actor:   actor,
+		Payload: teleportation.EventPayload{Step: session.StepIndex, Sharing: &report},
+	})
+}
+
+// sharingView limits the sharing record to what role may know: its own bits
+// and bases, every basis once they were announced and the announced outcome.
+// Bob and Charlie see each other's bits once they pooled their shares.
+func sharingView(session *teleportation.SessionState, role qubit.Role) *teleportation.SecretSharing {
+	s := session.Sharing
+	if s == nil {
+		return nil
+	}
+	view := &teleportation.SecretSharing{Valid: s.Valid, SharingReport: s.SharingReport}
+	switch role {
+	case qubit.RoleAlice:
+		view.AliceBits, view.AliceBases = s.AliceBits, s.AliceBases
+	case qubit.RoleBob:
+		view.BobBits, view.BobBases = s.BobBits, s.BobBases
+	case qubit.RoleCharlie:
+		view.CharlieBits, view.CharlieBases = s.CharlieBits, s.CharlieBases
+	}
+	if stepReached(session, teleportation.StepSift) {
+		view.AliceBases, view.BobBases, view.CharlieBases = s.AliceBases, s.BobBases, s.CharlieBases
+	}
+	if stepReached(session, teleportation.StepCombine) && (role == qubit.RoleBob || role == qubit.RoleCharlie) {
+		view.BobBits, view.CharlieBits = s.BobBits, s.CharlieBits
+	}
+	return view
+}
+
+func randomSharingBases(session *teleportation.SessionState, n int) string {
+	bases := make([]byte, n)
+	for i := range bases {
+		bases[i] = teleportation.BasisDiagonal
+		if session.Random.Float64() < 0.5 {
+			bases[i] = teleportation.BasisY
+		}
+	}
+	return string(bases)
+}
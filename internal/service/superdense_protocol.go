@@ -0,0 +1,199 @@
+package service
+
+import (
+	"errors"
+
+	"quantum-teleport/internal/domain/quantum"
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// Register layout of superdense coding: Alice's and Bob's halves of the Bell pair.
+const (
+	superdenseAlice = iota
+	superdenseBob
+)
+
+// superdenseProtocol lets Alice send two classical bits to Bob by applying a
+// Pauli operation to her half of a Bell pair and handing the qubit over; Bob
+// reads both bits back with a Bell measurement.
+type superdenseProtocol struct{}
+
+func (superdenseProtocol) Name() string {
+	return teleportation.ProtocolSuperdense
+}
+
+func (superdenseProtocol) Steps(teleportation.Config) []teleportation.StepInfo {
+	return []teleportation.StepInfo{
+		{Key: teleportation.StepEntangle, Title: "Подготовка запутанной пары", Description: "Алиса и Боб получают по половине пары Белла."},
+		{Key: teleportation.StepEncode, Title: "Кодирование Алисы", Description: "Алиса выбирает два бита и применяет к своей половине пары I, X, Z или XZ."},
+		{Key: teleportation.StepSendQubit, Title: "Передача кубита", Description: "Алиса отправляет Бобу свой кубит: один кубит несёт два бита."},
+		{Key: teleportation.StepDecode, Title: "Измерение Белла у Боба", Description: "Боб распутывает пару вентилями CNOT и H и измеряет оба кубита."},
+		{Key: teleportation.StepComplete, Title: "Готово", Description: "Боб прочитал два классических бита, получив один кубит."},
+	}
+}
+
+func (superdenseProtocol) Roles(teleportation.Config) []qubit.Role {
+	return []qubit.Role{qubit.RoleAlice, qubit.RoleBob}
+}
+
+func (superdenseProtocol) Allowed(step teleportation.StepInfo, role qubit.Role) bool {
+	switch step.Key {
+	case teleportation.StepEntangle:
+		return role == qubit.RoleAlice || role == qubit.RoleBob
+	case teleportation.StepEncode:
+		return role == qubit.RoleAlice
+	case teleportation.StepSendQubit, teleportation.StepDecode:
+		return role == qubit.RoleBob
+	default:
+		return false
+	}
+}
+
+// Validate refuses an initial state: superdense coding carries no unknown state.
+func (superdenseProtocol) Validate(config teleportation.Config) error {
+	if err := rejectUnusedOptions(config, 0); err != nil {
+		return err
+	}
+	return rejectInitialState(config)
+}
+
+// Prepare starts from |00>.
+func (superdenseProtocol) Prepare(session *teleportation.SessionState) {
+	session.HiddenState = qubit.BlochState{}
+	session.Register = blankRegister(session.Config, 2)
+	session.Qubits = []qubit.Qubit{
+		{ID: "q1", Role: qubit.RoleAlice, State: "Чистое состояние"},
+		{ID: "q2", Role: qubit.RoleBob, State: "Чистое состояние"},
+	}
+}
+
+func (superdenseProtocol) Ready(session *teleportation.SessionState) error {
+	if session.CurrentStep().Key == teleportation.StepEncode && session.Encoding == nil {
+		return errors.New("bits not encoded")
+	}
+	return nil
+}
+
+func (superdenseProtocol) Enter(session *teleportation.SessionState) {
+	register := session.Register
+	switch session.CurrentStep().Key {
+	case teleportation.StepEncode:
+		register.Apply(quantum.H, superdenseAlice)
+		register.CNOT(superdenseAlice, superdenseBob)
+		session.Qubits[0].State = "Половина пары Белла"
+		session.Qubits[1].State = "Половина пары Белла"
+	case teleportation.StepSendQubit:
+		// Alice's qubit changes hands; from here on only Bob sees it.
+		session.Qubits[0].Role = qubit.RoleBob
+		session.Qubits[0].State = "Получен от Алисы"
+	case teleportation.StepDecode:
+		// CNOT and H map the four Bell states onto |00>, |01>, |10> and |11>,
+		// so measuring both qubits reads Alice's bits in order.
+		register.CNOT(superdenseAlice, superdenseBob)
+		register.Apply(quantum.H, superdenseAlice)
+		m1, m2, p := register.MeasurePair(superdenseAlice, superdenseBob, session.Random.Float64())
+		measurement := teleportation.NewMeasurement(m1, m2, p)
+		session.Measurement = &measurement
+		session.Qubits[0].State = "Измерен"
+		session.Qubits[1].State = "Измерен"
+		session.Record(teleportation.Event{
+			Type:    teleportation.EventMeasurementTaken,
+			Actor:   qubit.RoleBob,
+			Payload: teleportation.EventPayload{Step: session.StepIndex, Measurement: &measurement},
+		})
+	case teleportation.StepComplete:
+		if session.Measurement.Bits() == session.Encoding.Bits {
+			session.Qubits[1].State = "Биты прочитаны: " + session.Measurement.Bits()
+		} else {
+			session.Qubits[1].State = "Биты искажены: " + session.Measurement.Bits()
+		}
+	}
+}
+
+func (superdenseProtocol) Sync(session *teleportation.SessionState) {
+	session.Qubits[0].Bloch = session.Register.Bloch(superdenseAlice)
+	session.Qubits[1].Bloch = session.Register.Bloch(superdenseBob)
+}
+
+// MeasurementVisible gives Bob the bits he decoded; Alice and the audience
+// learn them once the run is complete.
+func (superdenseProtocol) MeasurementVisible(session *teleportation.SessionState, role qubit.Role) bool {
+	if role == qubit.RoleBob {
+		return stepReached(session, teleportation.StepDecode)
+	}
+	return stepReached(session, teleportation.StepComplete)
+}
+
+// Replay re-applies Alice's encoding.
+func (superdenseProtocol) Replay(session *teleportation.SessionState, e teleportation.Event) (bool, error) {
+	if e.Type != teleportation.EventBitsEncoded {
+		return false, nil
+	}
+	if e.Payload.Encoding == nil || session.CurrentStep().Key != teleportation.StepEncode {
+		return true, errors.New("invalid encoding")
+	}
+	encoding, ok := teleportation.NewEncoding(e.Payload.Encoding.Bits)
+	if !ok {
+		return true, errors.New("invalid encoding")
+	}
+	encodeLocked(session, encoding)
+	return true, nil
+}
+
+// encodeLocked applies Alice's Pauli operation for the bits to her half of the
+// pair, first undoing a previous choice so she can change her mind.
+func encodeLocked(session *teleportation.SessionState, encoding teleportation.Encoding) teleportation.Encoding {
+	if previous := session.Encoding; previous != nil {
+		undoPauli(session.Register, superdenseAlice, previous.Operation)
+		encoding.Attempts = previous.Attempts + 1
+	}
+	applyPauli(session.Register, superdenseAlice, encoding.Operation)
+	session.Encoding = &encoding
+	// The label stays neutral: the operation would give the bits away to anyone reading the qubit list.
+	session.Qubits[0].State = "Закодирован"
+	superdenseProtocol{}.Sync(session)
+	return encoding
+}
+
+// EncodeBits lets Alice pack two classical bits such as "10" into her half of
+// the pair on the encode step of a superdense coding session.
+func (s *TeleportationService) EncodeBits(id string, token string, bits string) (*teleportation.SessionState, error) {
+	encoding, ok := teleportation.NewEncoding(bits)
+	if !ok {
+		return nil, errors.New("invalid bits")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions.Get(id)
+	if !ok {
+		return nil, errors.New("session not found")
+	}
+
+	role, err := s.validateTokenLocked(session, token)
+	if err != nil {
+		return nil, err
+	}
+	protocol, err := s.protocolFor(session)
+	if err != nil {
+		return nil, err
+	}
+	if current := session.CurrentStep(); current.Key != teleportation.StepEncode || !protocol.Allowed(current, role) {
+		return nil, errors.New("role not permitted for step")
+	}
+
+	applied := encodeLocked(session, encoding)
+	session.Record(teleportation.Event{
+		Type:    teleportation.EventBitsEncoded,
+		Actor:   role,
+		Payload: teleportation.EventPayload{Step: session.StepIndex, Encoding: &applied},
+	})
+	if err := s.saveLocked(session); err != nil {
+		return nil, err
+	}
+
+	s.broadcastLocked(session)
+	return session, nil
+}
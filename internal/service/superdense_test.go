@@ -0,0 +1,133 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+func TestSuperdenseCodingDeliversBothBits(t *testing.T) {
+	for _, bits := range []string{"00", "01", "10", "11"} {
+		service := NewTeleportationService()
+		session, err := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolSuperdense})
+		if err != nil {
+			t.Fatalf("expected a superdense session, got %v", err)
+		}
+		alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+		bob, _ := service.JoinSession(session.ID, qubit.RoleBob, "")
+
+		if _, err := service.EncodeBits(session.ID, alice.Token, bits); err == nil {
+			t.Fatal("expected encoding before the pair exists to be refused")
+		}
+		if _, err := service.AdvanceStep(session.ID, bob.Token); err != nil {
+			t.Fatalf("expected bob to share the pair, got %v", err)
+		}
+		if _, err := service.AdvanceStep(session.ID, alice.Token); err == nil {
+			t.Fatal("expected alice to encode before sending")
+		}
+		if _, err := service.EncodeBits(session.ID, bob.Token, bits); err == nil {
+			t.Fatal("expected bob not to encode")
+		}
+		if _, err := service.EncodeBits(session.ID, alice.Token, "11"); err != nil {
+			t.Fatalf("expected alice to encode, got %v", err)
+		}
+		if _, err := service.EncodeBits(session.ID, alice.Token, bits); err != nil {
+			t.Fatalf("expected alice to change her bits, got %v", err)
+		}
+		if session.Encoding.Attempts != 2 {
+			t.Fatalf("expected the second encoding to be counted, got %+v", session.Encoding)
+		}
+		for _, token := range []string{alice.Token, bob.Token, bob.Token} {
+			if _, err := service.AdvanceStep(session.ID, token); err != nil {
+				t.Fatalf("expected the run to continue, got %v", err)
+			}
+		}
+
+		if session.Measurement == nil || session.Measurement.Bits() != bits || session.Measurement.Probability < 1-1e-9 {
+			t.Fatalf("expected bob to decode %s with certainty, got %+v", bits, session.Measurement)
+		}
+		if session.Qubits[1].State != "Биты прочитаны: "+bits {
+			t.Fatalf("expected bob's qubit to report the bits, got %q", session.Qubits[1].State)
+		}
+
+		rebuilt, err := service.Rebuild(session.Events)
+		if err != nil || *rebuilt.Measurement != *session.Measurement || *rebuilt.Encoding != *session.Encoding {
+			t.Fatalf("expected the run to replay, got %+v, %v", rebuilt, err)
+		}
+	}
+}
+
+func TestSuperdenseEncodingStaysWithAlice(t *testing.T) {
+	service := NewTeleportationService()
+	session, _ := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolSuperdense})
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+	bob, _ := service.JoinSession(session.ID, qubit.RoleBob, "")
+	_, _ = service.AdvanceStep(session.ID, alice.Token)
+	_, _ = service.EncodeBits(session.ID, alice.Token, "10")
+	for _, token := range []string{bob.Token, ""} {
+		view, _ := service.SessionView(session.ID, token)
+		if view.Qubits[0].State != "Закодирован" {
+			t.Fatalf("expected alice's qubit to be labelled without its operation, got %q", view.Qubits[0].State)
+		}
+	}
+	_, _ = service.AdvanceStep(session.ID, alice.Token)
+
+	bobView, _ := service.SessionView(session.ID, bob.Token)
+	if bobView.Encoding != nil {
+		t.Fatal("expected bob not to see alice's bits")
+	}
+	if bobView.Qubits[0].Hidden {
+		t.Fatal("expected bob to see the qubit he received")
+	}
+	aliceView, _ := service.SessionView(session.ID, alice.Token)
+	if aliceView.Encoding == nil || !aliceView.Qubits[0].Hidden {
+		t.Fatalf("expected alice to keep her bits but lose the sent qubit, got %+v", aliceView)
+	}
+	_, _ = service.AdvanceStep(session.ID, bob.Token)
+	observer, _ := service.SessionView(session.ID, "")
+	if observer.Measurement != nil {
+		t.Fatal("expected the audience not to see the decoded bits before the run completes")
+	}
+	encoded, decoded := false, false
+	for _, line := range observer.Log {
+		encoded = encoded || line == "Алиса закодировала два бита в свою половину пары"
+		decoded = decoded || line == "Боб выполнил измерение Белла и прочитал два бита"
+		if strings.Contains(line, "10") {
+			t.Fatalf("expected the bits to stay out of the log, got %q", line)
+		}
+	}
+	if !encoded || !decoded {
+		t.Fatalf("expected the encoding and decoding in the log without their bits, got %v", observer.Log)
+	}
+}
+
+func TestProtocolsWithoutUnknownStateRefuseInitialState(t *testing.T) {
+	service := NewTeleportationService()
+	plus, _ := qubit.Preset("+")
+	for _, protocol := range []string{teleportation.ProtocolSuperdense, teleportation.ProtocolSwapping, teleportation.ProtocolBB84, teleportation.ProtocolSecretSharing} {
+		_, err := service.CreateSessionWithConfig(teleportation.Config{Protocol: protocol, InitialState: &plus})
+		if err == nil || err.Error() != "initial state not supported" {
+			t.Fatalf("expected %s to refuse an initial state, got %v", protocol, err)
+		}
+	}
+
+	session, _ := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolSuperdense})
+	bundle, err := service.ExportSession(session.ID, session.HostToken)
+	if err != nil || bundle.InitialState != nil {
+		t.Fatalf("expected a superdense bundle without an initial state, got %+v, %v", bundle.InitialState, err)
+	}
+	bundle.Events = nil
+	if _, err := service.ImportSession(bundle); err != nil {
+		t.Fatalf("expected the bundle to import as a scenario, got %v", err)
+	}
+}
+
+func TestSuperdenseRefusesTeleportationCircuit(t *testing.T) {
+	service := NewTeleportationService()
+	session, _ := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolSuperdense})
+	if _, err := service.Circuit(session.ID, ""); err == nil {
+		t.Fatal("expected no teleportation circuit for a superdense session")
+	}
+}
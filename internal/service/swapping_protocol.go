@@ -0,0 +1,154 @@
+package service
+
+import (
+	"errors"
+
+	"quantum-teleport/internal/domain/quantum"
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// Register layout of entanglement swapping: Alice's half of the first pair,
+// Charlie's halves of the first and second pair and Bob's half of the second.
+const (
+	swappingAlice = iota
+	swappingCharlieA
+	swappingCharlieB
+	swappingBob
+)
+
+// swappingProtocol entangles Alice and Bob through Charlie: a Bell measurement
+// on Charlie's two halves projects the outer qubits onto a Bell pair, which
+// Bob turns into |Φ+> with the Pauli correction Charlie's bits dictate.
+type swappingProtocol struct{}
+
+func (swappingProtocol) Name() string {
+	return teleportation.ProtocolSwapping
+}
+
+func (swappingProtocol) Steps(teleportation.Config) []teleportation.StepInfo {
+	return []teleportation.StepInfo{
+		{Key: teleportation.StepEntangle, Title: "Подготовка двух пар", Description: "Готовятся две независимые пары Белла: Алиса–Чарли и Чарли–Боб."},
+		{Key: teleportation.StepDistribute, Title: "Пары розданы", Description: "Алиса и Боб держат половины разных пар и никогда не взаимодействовали."},
+		{Key: teleportation.StepMeasure, Title: "Измерение Чарли", Description: "Чарли делает измерение Белла над своими половинами обеих пар."},
+		{Key: teleportation.StepSend, Title: "Классическая передача", Description: "Чарли сообщает Бобу два бита своего измерения."},
+		{Key: teleportation.StepReconstruct, Title: "Коррекция у Боба", Description: "Боб применяет коррекцию, и пара Алиса–Боб становится |Φ+⟩."},
+		{Key: teleportation.StepComplete, Title: "Готово", Description: "Запутанность передана: Алиса и Боб делят пару Белла, как узлы квантового повторителя."},
+	}
+}
+
+func (swappingProtocol) Roles(teleportation.Config) []qubit.Role {
+	return []qubit.Role{qubit.RoleAlice, qubit.RoleCharlie, qubit.RoleBob}
+}
+
+func (swappingProtocol) Allowed(step teleportation.StepInfo, role qubit.Role) bool {
+	switch step.Key {
+	case teleportation.StepEntangle:
+		return role == qubit.RoleAlice || role == qubit.RoleCharlie || role == qubit.RoleBob
+	case teleportation.StepDistribute, teleportation.StepMeasure:
+		return role == qubit.RoleCharlie
+	case teleportation.StepSend, teleportation.StepReconstruct:
+		return role == qubit.RoleBob
+	default:
+		return false
+	}
+}
+
+// Validate refuses an initial state: there is no unknown state to transfer.
+func (swappingProtocol) Validate(config teleportation.Config) error {
+	if err := rejectUnusedOptions(config, 0); err != nil {
+		return err
+	}
+	return rejectInitialState(config)
+}
+
+// Prepare starts from |0000>.
+func (swappingProtocol) Prepare(session *teleportation.SessionState) {
+	session.HiddenState = qubit.BlochState{}
+	session.Register = blankRegister(session.Config, 4)
+	session.Qubits = []qubit.Qubit{
+		{ID: "q1", Role: qubit.RoleAlice, State: "Чистое состояние"},
+		{ID: "q2", Role: qubit.RoleCharlie, State: "Чистое состояние"},
+		{ID: "q3", Role: qubit.RoleCharlie, State: "Чистое состояние"},
+		{ID: "q4", Role: qubit.RoleBob, State: "Чистое состояние"},
+	}
+}
+
+func (swappingProtocol) Ready(session *teleportation.SessionState) error {
+	if session.CurrentStep().Key == teleportation.StepReconstruct && session.Correction == nil {
+		return errors.New("correction not applied")
+	}
+	return nil
+}
+
+func (swappingProtocol) Enter(session *teleportation.SessionState) {
+	register := session.Register
+	switch session.CurrentStep().Key {
+	case teleportation.StepDistribute:
+		register.Apply(quantum.H, swappingAlice)
+		register.CNOT(swappingAlice, swappingCharlieA)
+		register.Apply(quantum.H, swappingCharlieB)
+		register.CNOT(swappingCharlieB, swappingBob)
+		session.Qubits[0].State = "Пара с Чарли"
+		session.Qubits[1].State = "Пара с Алисой"
+		session.Qubits[2].State = "Пара с Бобом"
+		session.Qubits[3].State = "Пара с Чарли"
+	case teleportation.StepMeasure:
+		// The same basis change as Alice's in teleportation turns the joint
+		// measurement of Charlie's qubits into a Bell measurement.
+		register.CNOT(swappingCharlieA, swappingCharlieB)
+		register.Apply(quantum.H, swappingCharlieA)
+		m1, m2, p := register.MeasurePair(swappingCharlieA, swappingCharlieB, session.Random.Float64())
+		measurement := teleportation.NewMeasurement(m1, m2, p)
+		session.Measurement = &measurement
+		session.Qubits[0].State = "Запутан с Бобом"
+		session.Qubits[1].State = "Измерен"
+		session.Qubits[2].State = "Измерен"
+		session.Qubits[3].State = "Запутан с Алисой"
+		session.Record(teleportation.Event{
+			Type:    teleportation.EventMeasurementTaken,
+			Actor:   qubit.RoleCharlie,
+			Payload: teleportation.EventPayload{Step: session.StepIndex, Measurement: &measurement},
+		})
+	case teleportation.StepSend:
+		bits := *session.Measurement
+		session.Record(teleportation.Event{
+			Type:    teleportation.EventBitsSent,
+			Actor:   qubit.RoleCharlie,
+			Payload: teleportation.EventPayload{Step: session.StepIndex, Measurement: &bits},
+		})
+	case teleportation.StepReconstruct:
+		session.Qubits[3].State = "Ожидает коррекцию"
+	case teleportation.StepComplete:
+		if session.Correction.Correct {
+			session.Qubits[3].State = "Пара |Φ+⟩ с Алисой"
+		} else {
+			session.Qubits[3].State = "Пара искажена"
+		}
+	}
+}
+
+func (swappingProtocol) Sync(session *teleportation.SessionState) {
+	for q := range session.Qubits {
+		session.Qubits[q].Bloch = session.Register.Bloch(q)
+	}
+}
+
+// MeasurementVisible gives Charlie his bits right after measuring and everyone
+// else once they were sent.
+func (swappingProtocol) MeasurementVisible(session *teleportation.SessionState, role qubit.Role) bool {
+	if role == qubit.RoleCharlie {
+		return stepReached(session, teleportation.StepMeasure)
+	}
+	return stepReached(session, teleportation.StepSend)
+}
+
+// Correct applies Bob's Pauli correction and scores the Alice–Bob pair against |Φ+>.
+func (p swappingProtocol) Correct(session *teleportation.SessionState, correction teleportation.Correction) teleportation.CorrectionAttempt {
+	attempt := correctPauliLocked(session, swappingBob, correction, session.Measurement.Correction, func() float64 {
+		return quantum.BellFidelity(session.Register, swappingAlice, swappingBob)
+	})
+	session.Qubits[3].State = "Коррекция применена: " + string(correction)
+	p.Sync(session)
+	return attempt
+}
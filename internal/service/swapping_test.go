@@ -0,0 +1,69 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"quantum-teleport/internal/domain/quantum"
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+func TestEntanglementSwappingLinksAliceAndBob(t *testing.T) {
+	seen := make(map[teleportation.Correction]bool)
+	for seed := int64(1); seed <= 12; seed++ {
+		service := NewTeleportationService(WithSeed(seed))
+		session, err := service.CreateSessionWithConfig(teleportation.Config{Protocol: teleportation.ProtocolSwapping})
+		if err != nil {
+			t.Fatalf("expected a swapping session, got %v", err)
+		}
+		if len(session.Participants) != 3 || len(session.Qubits) != 4 {
+			t.Fatalf("expected three roles and four qubits, got %d and %d", len(session.Participants), len(session.Qubits))
+		}
+		alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+		charlie, err := service.JoinSession(session.ID, qubit.RoleCharlie, "")
+		if err != nil {
+			t.Fatalf("expected charlie to join, got %v", err)
+		}
+		bob, _ := service.JoinSession(session.ID, qubit.RoleBob, "")
+
+		_, _ = service.AdvanceStep(session.ID, alice.Token)
+		if _, err := service.AdvanceStep(session.ID, alice.Token); err == nil {
+			t.Fatal("expected only charlie to measure")
+		}
+		if got := quantum.BellFidelity(session.Register, swappingAlice, swappingBob); math.Abs(got-0.25) > 1e-9 {
+			t.Fatalf("expected alice and bob to start uncorrelated, got fidelity %f", got)
+		}
+		for _, token := range []string{charlie.Token, charlie.Token, bob.Token} {
+			if _, err := service.AdvanceStep(session.ID, token); err != nil {
+				t.Fatalf("expected the relay to proceed, got %v", err)
+			}
+		}
+
+		correction := session.Measurement.Correction
+		seen[correction] = true
+		if _, err := service.ApplyCorrection(session.ID, alice.Token, correction); err == nil {
+			t.Fatal("expected only bob to correct")
+		}
+		if _, err := service.ApplyCorrection(session.ID, bob.Token, correction); err != nil {
+			t.Fatalf("expected bob to correct, got %v", err)
+		}
+		if !session.Correction.Correct || math.Abs(session.Correction.Fidelity-1) > 1e-9 {
+			t.Fatalf("expected a Φ+ pair after %s, got %+v", correction, session.Correction)
+		}
+		if _, err := service.AdvanceStep(session.ID, bob.Token); err != nil {
+			t.Fatalf("expected bob to complete, got %v", err)
+		}
+		if session.Qubits[0].Bloch.Radius > 1e-9 {
+			t.Fatalf("expected alice's qubit to stay maximally mixed, got %+v", session.Qubits[0].Bloch)
+		}
+
+		charlieView, _ := service.SessionView(session.ID, charlie.Token)
+		if charlieView.Qubits[1].Hidden || charlieView.Qubits[2].Hidden || !charlieView.Qubits[3].Hidden {
+			t.Fatalf("expected charlie to see only his two qubits, got %+v", charlieView.Qubits)
+		}
+	}
+	if len(seen) < 2 {
+		t.Fatalf("expected several Bell outcomes across seeds, got %v", seen)
+	}
+}
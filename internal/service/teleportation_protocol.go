@@ -0,0 +1,236 @@
+package service
+
+import (
+	"errors"
+
+	"quantum-teleport/internal/domain/quantum"
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
+)
+
+// Register layout of the teleportation circuit: Alice's unknown qubit, Alice's
+// half of the Bell pair and Bob's half of the Bell pair.
+const (
+	registerUnknown = iota
+	registerAliceHalf
+	registerBob
+)
+
+// teleportationProtocol is the default flow: Alice teleports an unknown qubit
+// to Bob through a shared Bell pair and two classical bits.
+type teleportationProtocol struct{}
+
+func (teleportationProtocol) Name() string {
+	return teleportation.ProtocolTeleportation
+}
+
+func (teleportationProtocol) Steps(config teleportation.Config) []teleportation.StepInfo {
+	steps := []teleportation.StepInfo{
+		{Key: teleportation.StepEntangle, Title: "Подготовка запутанной пары", Description: "Алиса или Боб создают общую пару кубитов для телепортации."},
+		{Key: teleportation.StepCombine, Title: "Объединение состояний", Description: "Алиса соединяет свой неизвестный кубит с полученной запутанной частицей."},
+		{Key: teleportation.StepMeasure, Title: "Измерение Алисы", Description: "Алиса делает парное измерение, разрушая исходное состояние."},
+	}
+	if config.Eavesdropper {
+		steps = append(steps, teleportation.StepInfo{Key: teleportation.StepIntercept, Title: "Перехват битов", Description: "Ева читает биты Алисы в канале и может подменить их: без аутентификации Боб этого не заметит."})
+	}
+	return append(steps,
+		teleportation.StepInfo{Key: teleportation.StepSend, Title: "Классическая передача", Description: "Результаты измерения отправляются Бобу по обычному каналу связи."},
+		teleportation.StepInfo{Key: teleportation.StepReconstruct, Title: "Восстановление у Боба", Description: "Боб применяет коррекции и получает состояние Алисы."},
+		teleportation.StepInfo{Key: teleportation.StepComplete, Title: "Готово", Description: "Состояние успешно перенесено, исходник уничтожен."},
+	)
+}
+
+func (teleportationProtocol) Roles(config teleportation.Config) []qubit.Role {
+	if config.Eavesdropper {
+		return []qubit.Role{qubit.RoleAlice, qubit.RoleBob, qubit.RoleEve}
+	}
+	return []qubit.Role{qubit.RoleAlice, qubit.RoleBob}
+}
+
+func (teleportationProtocol) Allowed(step teleportation.StepInfo, role qubit.Role) bool {
+	switch step.Key {
+	case teleportation.StepEntangle:
+		return role == qubit.RoleAlice || role == qubit.RoleBob
+	case teleportation.StepCombine:
+		return role == qubit.RoleAlice
+	case teleportation.StepMeasure:
+		return role == qubit.RoleAlice
+	case teleportation.StepIntercept:
+		return role == qubit.RoleEve
+	case teleportation.StepSend:
+		return role == qubit.RoleBob
+	case teleportation.StepReconstruct:
+		return role == qubit.RoleBob
+	case teleportation.StepComplete:
+		return false
+	default:
+		return false
+	}
+}
+
+func (teleportationProtocol) Validate(config teleportation.Config) error {
+	return rejectUnusedOptions(config, optionEavesdropper)
+}
+
+// Prepare draws the unknown state unless the config fixes it, so a
+// regenerate, which clears the configured state, draws a new one.
+func (teleportationProtocol) Prepare(session *teleportation.SessionState) {
+	if session.Config.InitialState != nil {
+		session.HiddenState = *session.Config.InitialState
+	} else {
+		session.HiddenState = randomBlochState(session.Random)
+	}
+	session.Register = newRegister(session.Config, session.HiddenState)
+	session.Qubits = initialQubits()
+}
+
+func (teleportationProtocol) Ready(session *teleportation.SessionState) error {
+	if session.CurrentStep().Key == teleportation.StepReconstruct && session.Correction == nil {
+		return errors.New("correction not applied")
+	}
+	return nil
+}
+
+func (teleportationProtocol) Enter(session *teleportation.SessionState) {
+	register := session.Register
+	switch session.CurrentStep().Key {
+	case teleportation.StepCombine:
+		// Leaving the entangle step shares a Bell pair between Alice and Bob,
+		// then Alice links her unknown qubit to her half with a CNOT.
+		register.Apply(quantum.H, registerAliceHalf)
+		register.CNOT(registerAliceHalf, registerBob)
+		register.CNOT(registerUnknown, registerAliceHalf)
+		session.Qubits[0].State = "Связан с парой"
+		session.Qubits[1].State = "Запутанная пара готова"
+	case teleportation.StepMeasure:
+		// H after Alice's CNOT rotates the Bell basis onto the computational one,
+		// so sampling both qubits jointly is a Bell measurement.
+		register.Apply(quantum.H, registerUnknown)
+		m1, m2, p := register.MeasurePair(registerUnknown, registerAliceHalf, session.Random.Float64())
+		measurement := teleportation.NewMeasurement(m1, m2, p)
+		session.Measurement = &measurement
+		session.Qubits[0].State = "Измерен"
+		session.Record(teleportation.Event{
+			Type:    teleportation.EventMeasurementTaken,
+			Actor:   qubit.RoleAlice,
+			Payload: teleportation.EventPayload{Step: session.StepIndex, Measurement: &measurement},
+		})
+	case teleportation.StepIntercept:
+		bits := *session.Measurement
+		interceptLocked(session, "00")
+		session.Record(teleportation.Event{
+			Type:    teleportation.EventBitsIntercepted,
+			Actor:   qubit.RoleEve,
+			Payload: teleportation.EventPayload{Step: session.StepIndex, Measurement: &bits},
+		})
+	case teleportation.StepSend:
+		bits := deliveredBits(session)
+		session.Record(teleportation.Event{
+			Type:    teleportation.EventBitsSent,
+			Actor:   qubit.RoleAlice,
+			Payload: teleportation.EventPayload{Step: session.StepIndex, Measurement: &bits},
+		})
+	case teleportation.StepReconstruct:
+		session.Qubits[1].State = "Ожидает коррекцию"
+	case teleportation.StepComplete:
+		if session.Correction.Correct {
+			session.Qubits[1].State = "Состояние восстановлено"
+		} else {
+			session.Qubits[1].State = "Состояние искажено"
+		}
+	}
+}
+
+// Noiseless exempts Eve's intercept step: it is optional and touches only
+// classical bits, so a session with her decoheres exactly as much as one
+// without, and any further loss is her doing.
+func (teleportationProtocol) Noiseless(step teleportation.StepInfo) bool {
+	return step.Key == teleportation.StepIntercept
+}
+
+func (teleportationProtocol) Sync(session *teleportation.SessionState) {
+	session.Qubits[0].Bloch = session.Register.Bloch(registerUnknown)
+	session.Qubits[1].Bloch = session.Register.Bloch(registerBob)
+}
+
+// MeasurementVisible gives Alice her bits right after measuring, Eve once she
+// intercepted them and everyone else once they were sent.
+func (teleportationProtocol) MeasurementVisible(session *teleportation.SessionState, role qubit.Role) bool {
+	switch role {
+	case qubit.RoleAlice:
+		return stepReached(session, teleportation.StepMeasure)
+	case qubit.RoleEve:
+		return stepReached(session, teleportation.StepIntercept)
+	}
+	return stepReached(session, teleportation.StepSend)
+}
+
+// newRegister prepares the unknown state on a fresh register: a state vector,
+// or a density matrix when the session is noisy.
+func newRegister(config teleportation.Config, unknown qubit.BlochState) quantum.Register {
+	pure := quantum.NewStateVector(3)
+	pure.Apply(quantum.Prepare(unknown), registerUnknown)
+	if config.Noise != nil {
+		return quantum.DensityFromStateVector(pure)
+	}
+	return pure
+}
+
+// initialQubits returns the displayed qubits before any step was taken.
+func initialQubits() []qubit.Qubit {
+	return []qubit.Qubit{
+		{ID: "q1", Role: qubit.RoleAlice, State: "Неизвестное состояние"},
+		{ID: "q2", Role: qubit.RoleBob, State: "Чистое состояние"},
+	}
+}
+
+// Correct applies Bob's Pauli correction and scores it against the hidden state.
+func (p teleportationProtocol) Correct(session *teleportation.SessionState, correction teleportation.Correction) teleportation.CorrectionAttempt {
+	attempt := correctPauliLocked(session, registerBob, correction, session.Measurement.Correction, func() float64 {
+		return quantum.Fidelity(session.Register.Reduced(registerBob), session.HiddenState)
+	})
+	session.Qubits[1].State = "Коррекция применена: " + string(correction)
+	p.Sync(session)
+	return attempt
+}
+
+// Replay re-applies Eve's choice on the intercept step; reading the bits
+// follows from entering it.
+func (teleportationProtocol) Replay(session *teleportation.SessionState, e teleportation.Event) (bool, error) {
+	switch e.Type {
+	case teleportation.EventBitsIntercepted:
+		return true, nil
+	case teleportation.EventBitsTampered:
+		interception := e.Payload.Interception
+		if interception == nil || session.Measurement == nil || session.CurrentStep().Key != teleportation.StepIntercept {
+			return true, errors.New("invalid interception")
+		}
+		if _, ok := teleportation.NewInterception(*session.Measurement, interception.Flip); !ok {
+			return true, errors.New("invalid interception")
+		}
+		interceptLocked(session, interception.Flip)
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// applyPauli applies the Pauli gates named by ops to qubit q in order.
+func applyPauli(register quantum.Register, q int, ops teleportation.Correction) {
+	for _, gate := range ops {
+		switch gate {
+		case 'X':
+			register.Apply(quantum.X, q)
+		case 'Z':
+			register.Apply(quantum.Z, q)
+		}
+	}
+}
+
+// undoPauli reverts applyPauli; Pauli gates are self-inverse, so it replays them backwards.
+func undoPauli(register quantum.Register, q int, ops teleportation.Correction) {
+	gates := []rune(string(ops))
+	for i := len(gates) - 1; i >= 0; i-- {
+		applyPauli(register, q, teleportation.Correction(gates[i]))
+	}
+}
@@ -4,11 +4,14 @@ import (
 	"errors"
 	"math"
 	"math/rand"
+	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
 	"github.com/gorilla/websocket"
 
+	"quantum-teleport/internal/domain/quantum"
 	"quantum-teleport/internal/domain/qubit"
 	"quantum-teleport/internal/domain/teleportation"
 	"quantum-teleport/pkg/utils"
@@ -16,73 +19,152 @@ import (
 
 // TeleportationService manages teleportation sessions and broadcasts.
 type TeleportationService struct {
-	mu         sync.RWMutex
-	sessions   map[string]*teleportation.SessionState
-	listeners  map[string]map[*websocket.Conn]*listener
-	stepPreset []teleportation.StepInfo
-	ttl        time.Duration
+	mu        sync.RWMutex
+	sessions  SessionStore
+	listeners map[string]map[*websocket.Conn]*listener
+	protocols map[string]Protocol
+	ttl       time.Duration
+	idle      time.Duration
+	seeds     *rand.Rand
 }
 
-type listener struct {
-	role qubit.Role
-	mu   sync.Mutex
+// Option customises a TeleportationService at construction.
+type Option func(*TeleportationService)
+
+// WithRandSource makes the service draw session seeds from src.
+func WithRandSource(src rand.Source) Option {
+	return func(s *TeleportationService) {
+		s.seeds = rand.New(src)
+	}
+}
+
+// WithStore replaces the default in-memory session store.
+func WithStore(store SessionStore) Option {
+	return func(s *TeleportationService) {
+		s.sessions = store
+	}
 }
 
-// NewTeleportationService constructs a service with default steps.
-func NewTeleportationService() *TeleportationService {
-	steps := []teleportation.StepInfo{
-		{Key: teleportation.StepEntangle, Title: "Подготовка запутанной пары", Description: "Алиса или Боб создают общую пару кубитов для телепортации."},
-		{Key: teleportation.StepCombine, Title: "Объединение состояний", Description: "Алиса соединяет свой неизвестный кубит с полученной запутанной частицей."},
-		{Key: teleportation.StepMeasure, Title: "Измерение Алисы", Description: "Алиса делает парное измерение, разрушая исходное состояние."},
-		{Key: teleportation.StepSend, Title: "Классическая передача", Description: "Результаты измерения отправляются Бобу по обычному каналу связи."},
-		{Key: teleportation.StepReconstruct, Title: "Восстановление у Боба", Description: "Боб применяет коррекции и получает состояние Алисы."},
-		{Key: teleportation.StepComplete, Title: "Готово", Description: "Состояние успешно перенесено, исходник уничтожен."},
+// WithRoleTTL sets how long a disconnected role stays reserved for its token.
+func WithRoleTTL(ttl time.Duration) Option {
+	return func(s *TeleportationService) {
+		s.ttl = ttl
 	}
+}
+
+// WithIdleTimeout sets how long a session may stay without activity before the janitor deletes it.
+func WithIdleTimeout(idle time.Duration) Option {
+	return func(s *TeleportationService) {
+		s.idle = idle
+	}
+}
+
+// WithSeed makes session seeds, and therefore every session, reproducible.
+func WithSeed(seed int64) Option {
+	return WithRandSource(rand.NewSource(seed))
+}
 
-	return &TeleportationService{
-		sessions:   make(map[string]*teleportation.SessionState),
-		listeners:  make(map[string]map[*websocket.Conn]*listener),
-		stepPreset: steps,
-		ttl:        60 * time.Second,
+type listener struct {
+	role  qubit.Role
+	token string
+	mu    sync.Mutex
+}
+
+// NewTeleportationService constructs a service running every built-in
+// protocol, listed in its registry below, and any protocol registered with
+// WithProtocol.
+func NewTeleportationService(options ...Option) *TeleportationService {
+	s := &TeleportationService{
+		sessions:  NewMemoryStore(),
+		listeners: make(map[string]map[*websocket.Conn]*listener),
+		protocols: map[string]Protocol{
+			teleportation.ProtocolTeleportation: teleportationProtocol{},
+			teleportation.ProtocolSuperdense:    superdenseProtocol{},
+			teleportation.ProtocolSwapping:      swappingProtocol{},
+			teleportation.ProtocolChain:         chainProtocol{},
+			teleportation.ProtocolBB84:          bb84Protocol{},
+			teleportation.ProtocolControlled:    controlledProtocol{},
+			teleportation.ProtocolSecretSharing: sharingProtocol{},
+		},
+		ttl:   60 * time.Second,
+		idle:  2 * time.Hour,
+		seeds: rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+	for _, option := range options {
+		option(s)
 	}
+	return s
 }
 
-// CreateSession initializes a teleportation session.
+// CreateSession initializes an ideal teleportation session.
 func (s *TeleportationService) CreateSession() (*teleportation.SessionState, error) {
+	return s.CreateSessionWithConfig(teleportation.Config{})
+}
+
+// CreateSessionWithConfig initializes a teleportation session with the given options.
+// A noise profile switches the simulation to a density matrix.
+func (s *TeleportationService) CreateSessionWithConfig(config teleportation.Config) (*teleportation.SessionState, error) {
+	if config.Protocol == "" {
+		config.Protocol = teleportation.ProtocolTeleportation
+	}
+	protocol, ok := s.protocols[config.Protocol]
+	if !ok {
+		return nil, errors.New("unsupported protocol")
+	}
+	if err := validateConfig(protocol, config); err != nil {
+		return nil, err
+	}
+	if config.InitialState != nil {
+		initial, err := qubit.NewBlochState(config.InitialState.Theta, config.InitialState.Phi)
+		if err != nil {
+			return nil, errors.New("invalid initial state")
+		}
+		config.InitialState = &initial
+	}
+	if config.Noise != nil {
+		if _, err := quantum.Kraus(config.Noise.Channel, config.Noise.Probability); err != nil {
+			return nil, errors.New("invalid noise profile")
+		}
+	}
+
 	id, err := utils.NewID()
 	if err != nil {
 		return nil, err
 	}
 
-	unknownState := randomBlochState()
-	bobBase := qubit.BlochState{Theta: 0, Phi: 0}
-
-	participants := map[qubit.Role]teleportation.Participant{
-		qubit.RoleAlice: {Role: qubit.RoleAlice, Taken: false},
-		qubit.RoleBob:   {Role: qubit.RoleBob, Taken: false},
+	if config.Seed == nil {
+		s.mu.Lock()
+		seed := s.seeds.Int63()
+		s.mu.Unlock()
+		config.Seed = &seed
+	}
+	hostToken, err := utils.NewID()
+	if err != nil {
+		return nil, err
 	}
 
 	now := time.Now()
-	session := &teleportation.SessionState{
-		ID:           id,
-		StepIndex:    0,
-		Steps:        append([]teleportation.StepInfo{}, s.stepPreset...),
-		Participants: participants,
-		HiddenState:  unknownState,
-		Qubits: []qubit.Qubit{
-			{ID: "q1", Role: qubit.RoleAlice, State: "Неизвестное состояние", Bloch: unknownState},
-			{ID: "q2", Role: qubit.RoleBob, State: "Чистое состояние", Bloch: bobBase},
-		},
-		Log: []string{"Сессия создана, роли свободны."},
-	}
+	session := newSessionLocked(protocol, config, utils.NewSeededRand(*config.Seed))
+	session.ID = id
+	session.HostToken = hostToken
+	unknownState := session.HiddenState
+	created := config
+	session.Record(teleportation.Event{
+		Type:    teleportation.EventSessionCreated,
+		At:      now,
+		Payload: teleportation.EventPayload{Config: &created, State: &unknownState},
+	})
+	session.Checkpoint()
 
 	s.mu.Lock()
+	defer s.mu.Unlock()
 	for role, p := range session.Participants {
 		p.LastSeen = now
 		session.Participants[role] = p
 	}
-	s.sessions[id] = session
-	s.mu.Unlock()
+	if err := s.saveLocked(session); err != nil {
+		return nil, err
+	}
 
 	return session, nil
 }
@@ -91,7 +173,7 @@ func (s *TeleportationService) CreateSession() (*teleportation.SessionState, err
 func (s *TeleportationService) GetSession(id string) (*teleportation.SessionState, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
-	session, ok := s.sessions[id]
+	session, ok := s.sessions.Get(id)
 	if !ok {
 		return nil, errors.New("session not found")
 	}
@@ -103,7 +185,7 @@ func (s *TeleportationService) JoinSession(id string, role qubit.Role, existingT
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	session, ok := s.sessions[id]
+	session, ok := s.sessions.Get(id)
 	if !ok {
 		return teleportation.Participant{}, errors.New("session not found")
 	}
@@ -116,6 +198,9 @@ func (s *TeleportationService) JoinSession(id string, role qubit.Role, existingT
 		if existingToken != "" && existingToken == participant.Token {
 			participant.LastSeen = time.Now()
 			session.Participants[role] = participant
+			if err := s.saveLocked(session); err != nil {
+				return teleportation.Participant{}, err
+			}
 			s.broadcastLocked(session)
 			return participant, nil
 		}
@@ -123,6 +208,9 @@ func (s *TeleportationService) JoinSession(id string, role qubit.Role, existingT
 			return teleportation.Participant{}, errors.New("role already taken")
 		}
 	}
+	if session.Locked {
+		return teleportation.Participant{}, errors.New("session locked")
+	}
 
 	token, err := utils.NewID()
 	if err != nil {
@@ -132,8 +220,16 @@ func (s *TeleportationService) JoinSession(id string, role qubit.Role, existingT
 	participant.Taken = true
 	participant.LastSeen = time.Now()
 	session.Participants[role] = participant
-	session.Log = append(session.Log, "Роль закреплена: "+string(role))
+	session.Record(teleportation.Event{
+		Type:    teleportation.EventRoleJoined,
+		Actor:   role,
+		Payload: teleportation.EventPayload{Step: session.StepIndex, Role: role},
+	})
+	if err := s.saveLocked(session); err != nil {
+		return teleportation.Participant{}, err
+	}
 
+	s.infoLocked(session, InfoEvent{Event: InfoRoleJoined, Role: role}, nil)
 	s.broadcastLocked(session)
 	return participant, nil
 }
@@ -143,7 +239,7 @@ func (s *TeleportationService) AdvanceStep(id string, token string) (*teleportat
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	session, ok := s.sessions[id]
+	session, ok := s.sessions.Get(id)
 	if !ok {
 		return nil, errors.New("session not found")
 	}
@@ -157,48 +253,128 @@ func (s *TeleportationService) AdvanceStep(id string, token string) (*teleportat
 		return session, nil
 	}
 
-	current := session.CurrentStep().Key
-	if !allowedForStep(current, role) {
+	protocol, err := s.protocolFor(session)
+	if err != nil {
+		return nil, err
+	}
+	if !protocol.Allowed(session.CurrentStep(), role) {
 		return nil, errors.New("role not permitted for step")
 	}
+	if err := protocol.Ready(session); err != nil {
+		return nil, err
+	}
 
 	session.StepIndex++
-	session.Log = append(session.Log, "Шаг: "+session.CurrentStep().Title)
-
-	switch session.CurrentStep().Key {
-	case teleportation.StepEntangle:
-		session.Qubits[1].State = "Запутанная пара готова"
-		session.Qubits[1].Bloch = equatorBloch(session.HiddenState.Phi + math.Pi/2)
-	case teleportation.StepCombine:
-		session.Qubits[0].State = "Связан с парой"
-		session.Qubits[1].Bloch = equatorBloch(session.HiddenState.Phi + math.Pi/3)
-	case teleportation.StepMeasure:
-		session.Qubits[0].State = "Измерен"
-		session.Qubits[0].Bloch = collapseBloch(session.HiddenState)
-	case teleportation.StepSend:
-		session.Log = append(session.Log, "Классические биты отправлены Бобу")
-	case teleportation.StepReconstruct:
-		session.Qubits[1].State = "Получает коррекцию"
-		session.Qubits[1].Bloch = session.HiddenState
-	case teleportation.StepComplete:
-		session.Qubits[1].State = "Состояние восстановлено"
-		session.Qubits[1].Bloch = session.HiddenState
+	session.Record(teleportation.Event{
+		Type:    teleportation.EventStepAdvanced,
+		Actor:   role,
+		Payload: teleportation.EventPayload{Step: session.StepIndex, Title: session.CurrentStep().Title},
+	})
+
+	enterStepLocked(protocol, session)
+	session.Checkpoint()
+	if err := s.saveLocked(session); err != nil {
+		return nil, err
+	}
+
+	s.broadcastLocked(session)
+	return session, nil
+}
+
+// ApplyCorrection lets Bob apply a Pauli correction on the reconstruct step of
+// a protocol that has one.
+// A repeated choice first undoes the previous one, so Bob can retry before advancing.
+func (s *TeleportationService) ApplyCorrection(id string, token string, correction teleportation.Correction) (*teleportation.SessionState, error) {
+	if !correction.Valid() {
+		return nil, errors.New("invalid correction")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions.Get(id)
+	if !ok {
+		return nil, errors.New("session not found")
+	}
+
+	role, err := s.validateTokenLocked(session, token)
+	if err != nil {
+		return nil, err
+	}
+	protocol, err := s.protocolFor(session)
+	if err != nil {
+		return nil, err
+	}
+	fixer, ok := protocol.(corrector)
+	if current := session.CurrentStep(); !ok || current.Key != teleportation.StepReconstruct || !protocol.Allowed(current, role) {
+		return nil, errors.New("role not permitted for step")
+	}
+
+	attempt := fixer.Correct(session, correction)
+	session.Record(teleportation.Event{
+		Type:    teleportation.EventCorrectionApplied,
+		Actor:   role,
+		Payload: teleportation.EventPayload{Step: session.StepIndex, Correction: &attempt},
+	})
+	if err := s.saveLocked(session); err != nil {
+		return nil, err
 	}
 
 	s.broadcastLocked(session)
 	return session, nil
 }
 
+// maxAnnotationLength bounds chat lines and notes, in characters.
+const maxAnnotationLength = 500
+
+// Annotate shares a chat line or note from a participant with all listeners of the session.
+func (s *TeleportationService) Annotate(id string, token string, text string) error {
+	text = strings.TrimSpace(text)
+	if text == "" || utf8.RuneCountInString(text) > maxAnnotationLength {
+		return errors.New("invalid annotation")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions.Get(id)
+	if !ok {
+		return errors.New("session not found")
+	}
+	role, err := s.validateTokenLocked(session, token)
+	if err != nil {
+		return err
+	}
+
+	annotation := Annotation{Role: role, Text: text, At: time.Now()}
+	for conn, entry := range s.listeners[session.ID] {
+		entry.mu.Lock()
+		_ = conn.WriteJSON(BroadcastMessage{Type: "annotation", Annotation: &annotation})
+		entry.mu.Unlock()
+	}
+	return nil
+}
+
 // LeaveSession releases a participant role and broadcasts the update.
 func (s *TeleportationService) LeaveSession(id string, token string) (*teleportation.SessionState, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	session, ok := s.sessions[id]
+	session, ok := s.sessions.Get(id)
 	if !ok {
 		return nil, errors.New("session not found")
 	}
 
+	if _, ok := session.Observers[token]; ok {
+		s.removeObserverLocked(session, token)
+		// Saved without stamping UpdatedAt: spectators do not keep a session alive.
+		if err := s.sessions.Save(session); err != nil {
+			return nil, err
+		}
+		s.broadcastLocked(session)
+		return session, nil
+	}
+
 	var role qubit.Role
 	for r, p := range session.Participants {
 		if p.Token == token {
@@ -210,65 +386,125 @@ func (s *TeleportationService) LeaveSession(id string, token string) (*teleporta
 		return nil, errors.New("unknown participant token")
 	}
 
+	s.releaseRoleLocked(session, role)
+	session.Record(teleportation.Event{
+		Type:    teleportation.EventRoleLeft,
+		Actor:   role,
+		Payload: teleportation.EventPayload{Step: session.StepIndex, Role: role, Reason: teleportation.LeaveVoluntary},
+	})
+	if err := s.saveLocked(session); err != nil {
+		return nil, err
+	}
+
+	s.infoLocked(session, InfoEvent{Event: InfoRoleLeft, Role: role}, nil)
+	s.broadcastLocked(session)
+	return session, nil
+}
+
+// releaseRoleLocked frees a role and closes the sockets bound to it.
+func (s *TeleportationService) releaseRoleLocked(session *teleportation.SessionState, role qubit.Role) {
 	participant := session.Participants[role]
 	participant.Token = ""
 	participant.Taken = false
 	participant.Connected = false
 	participant.LastSeen = time.Now()
 	session.Participants[role] = participant
-	session.Log = append(session.Log, "Роль освобождена: "+string(role))
 
-	conns := s.listeners[id]
+	conns := s.listeners[session.ID]
+	closing := websocket.FormatCloseMessage(websocket.CloseNormalClosure, "role released")
 	for conn, entry := range conns {
 		if entry.role == role {
+			_ = conn.WriteControl(websocket.CloseMessage, closing, time.Now().Add(time.Second))
 			_ = conn.Close()
 			delete(conns, conn)
 		}
 	}
-
-	s.broadcastLocked(session)
-	return session, nil
-}
-
-func allowedForStep(step teleportation.Step, role qubit.Role) bool {
-	switch step {
-	case teleportation.StepEntangle:
-		return role == qubit.RoleAlice || role == qubit.RoleBob
-	case teleportation.StepCombine:
-		return role == qubit.RoleAlice
-	case teleportation.StepMeasure:
-		return role == qubit.RoleAlice
-	case teleportation.StepSend:
-		return role == qubit.RoleBob
-	case teleportation.StepReconstruct:
-		return role == qubit.RoleBob
-	case teleportation.StepComplete:
-		return false
-	default:
-		return false
-	}
 }
 
+// validateTokenLocked resolves the participant allowed to act with the token.
 func (s *TeleportationService) validateTokenLocked(session *teleportation.SessionState, token string) (qubit.Role, error) {
 	for role, p := range session.Participants {
 		if p.Token == token {
 			return role, nil
 		}
 	}
+	if _, ok := session.Observers[token]; ok {
+		return "", errors.New("observers are read-only")
+	}
+	if token != "" && token == session.HostToken {
+		return "", errors.New("host is not a participant")
+	}
 	return "", errors.New("unknown participant token")
 }
 
-// RegisterListener binds a WebSocket connection to a session.
+// listenerRoleLocked resolves who may listen with the token: a participant,
+// an observer or the host, who listens as the instructor.
+func (s *TeleportationService) listenerRoleLocked(session *teleportation.SessionState, token string) (qubit.Role, error) {
+	if token != "" && token == session.HostToken {
+		return qubit.RoleInstructor, nil
+	}
+	if _, ok := session.Observers[token]; ok && token != "" {
+		return qubit.RoleObserver, nil
+	}
+	return s.validateTokenLocked(session, token)
+}
+
+// ObserveSession admits a read-only spectator and returns its token.
+// Any number of observers may watch a session; the janitor forgets a token
+// once it is older than the role TTL and no socket is open with it.
+func (s *TeleportationService) ObserveSession(id string) (teleportation.Observer, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	session, ok := s.sessions.Get(id)
+	if !ok {
+		return teleportation.Observer{}, errors.New("session not found")
+	}
+
+	token, err := utils.NewID()
+	if err != nil {
+		return teleportation.Observer{}, err
+	}
+	observer := teleportation.Observer{Token: token, JoinedAt: time.Now()}
+	if session.Observers == nil {
+		session.Observers = make(map[string]teleportation.Observer)
+	}
+	session.Observers[token] = observer
+	session.ObserverCount = len(session.Observers)
+	// Saved without stamping UpdatedAt: anyone may observe, and spectators
+	// must not keep an idle session alive.
+	if err := s.sessions.Save(session); err != nil {
+		return teleportation.Observer{}, err
+	}
+
+	s.broadcastLocked(session)
+	return observer, nil
+}
+
+// removeObserverLocked forgets an observer token and closes its sockets.
+func (s *TeleportationService) removeObserverLocked(session *teleportation.SessionState, token string) {
+	delete(session.Observers, token)
+	session.ObserverCount = len(session.Observers)
+	for conn, entry := range s.listeners[session.ID] {
+		if entry.token == token {
+			_ = conn.Close()
+			delete(s.listeners[session.ID], conn)
+		}
+	}
+}
+
+// RegisterListener binds a WebSocket connection to a session and returns the
+// session as the connecting role may see it.
 func (s *TeleportationService) RegisterListener(sessionID string, token string, conn *websocket.Conn) (*teleportation.SessionState, qubit.Role, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	session, ok := s.sessions[sessionID]
+	session, ok := s.sessions.Get(sessionID)
 	if !ok {
 		return nil, "", errors.New("session not found")
 	}
 
-	role, err := s.validateTokenLocked(session, token)
+	role, err := s.listenerRoleLocked(session, token)
 	if err != nil {
 		return nil, "", err
 	}
@@ -276,39 +512,62 @@ func (s *TeleportationService) RegisterListener(sessionID string, token string,
 	if _, exists := s.listeners[sessionID]; !exists {
 		s.listeners[sessionID] = make(map[*websocket.Conn]*listener)
 	}
-	s.listeners[sessionID][conn] = &listener{role: role}
+	s.listeners[sessionID][conn] = &listener{role: role, token: token}
+	participant, isParticipant := session.Participants[role]
+	if !isParticipant {
+		return projectLocked(s.protocols[session.Config.Protocol], session, role), role, nil
+	}
 
-	participant := session.Participants[role]
 	participant.Connected = true
 	participant.LastSeen = time.Now()
 	session.Participants[role] = participant
+	if err := s.saveLocked(session); err != nil {
+		delete(s.listeners[sessionID], conn)
+		s.reloadLocked(sessionID)
+		return nil, "", err
+	}
+	s.infoLocked(session, InfoEvent{Event: InfoRoleConnected, Role: role}, conn)
 
-	return session, role, nil
+	return projectLocked(s.protocols[session.Config.Protocol], session, role), role, nil
 }
 
-// UnregisterListener removes a WebSocket connection from updates.
-func (s *TeleportationService) UnregisterListener(sessionID string, conn *websocket.Conn) {
+// UnregisterListener removes a WebSocket connection from updates. The socket
+// is gone even when saving the disconnect fails; the error is returned so the
+// caller can report it.
+func (s *TeleportationService) UnregisterListener(sessionID string, conn *websocket.Conn) error {
 	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	entry, ok := s.listeners[sessionID][conn]
+	delete(s.listeners[sessionID], conn)
+	session, exists := s.sessions.Get(sessionID)
+	if !exists {
+		return nil
+	}
+	var role qubit.Role
 	if ok {
-		participant := s.sessions[sessionID].Participants[entry.role]
+		role = entry.role
+	}
+	var err error
+	if participant, isParticipant := session.Participants[role]; isParticipant {
 		participant.Connected = false
 		participant.LastSeen = time.Now()
-		s.sessions[sessionID].Participants[entry.role] = participant
-	}
-	delete(s.listeners[sessionID], conn)
-	session := s.sessions[sessionID]
-	if session != nil {
-		s.broadcastLocked(session)
+		session.Participants[role] = participant
+		if err = s.saveLocked(session); err != nil {
+			if session, exists = s.reloadLocked(sessionID); !exists {
+				return err
+			}
+		}
+		s.infoLocked(session, InfoEvent{Event: InfoRoleDisconnected, Role: role}, nil)
 	}
-	s.mu.Unlock()
+	s.broadcastLocked(session)
+	return err
 }
 
 // Broadcast pushes the latest session view to all listeners.
 func (s *TeleportationService) Broadcast(sessionID string) {
 	s.mu.RLock()
-	session := s.sessions[sessionID]
-	if session != nil {
+	if session, ok := s.sessions.Get(sessionID); ok {
 		s.broadcastLocked(session)
 	}
 	s.mu.RUnlock()
@@ -316,51 +575,130 @@ func (s *TeleportationService) Broadcast(sessionID string) {
 
 // LocalView carries role-specific data.
 type LocalView struct {
-	Role  qubit.Role `json:"role"`
-	State string     `json:"state"`
+	Role        qubit.Role                 `json:"role"`
+	State       string                     `json:"state"`
+	Measurement *teleportation.Measurement `json:"measurement,omitempty"`
 }
 
-// BroadcastMessage wraps global and local data for clients.
+// BroadcastMessage wraps global and local data for clients. RequestID
+// correlates ack and error replies with the client command that caused them.
 type BroadcastMessage struct {
-	Type   string                      `json:"type"`
-	Global *teleportation.SessionState `json:"global"`
-	Local  LocalView                   `json:"local"`
+	Type       string                      `json:"type"`
+	Global     *teleportation.SessionState `json:"global,omitempty"`
+	Local      LocalView                   `json:"local"`
+	RequestID  string                      `json:"requestId,omitempty"`
+	Error      string                      `json:"error,omitempty"`
+	Info       *InfoEvent                  `json:"info,omitempty"`
+	Annotation *Annotation                 `json:"annotation,omitempty"`
+}
+
+// Info event names pushed to listeners when roles change.
+const (
+	InfoRoleJoined       = "role_joined"
+	InfoRoleLeft         = "role_left"
+	InfoRoleReleased     = "role_released"
+	InfoRoleConnected    = "role_connected"
+	InfoRoleDisconnected = "role_disconnected"
+)
+
+// InfoEvent is a service notification about a session participant.
+type InfoEvent struct {
+	Event string     `json:"event"`
+	Role  qubit.Role `json:"role"`
+}
+
+// Annotation is a chat line or note shared with everyone watching a session.
+type Annotation struct {
+	Role qubit.Role `json:"role"`
+	Text string     `json:"text"`
+	At   time.Time  `json:"at"`
 }
 
-func randomBlochState() qubit.BlochState {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+func randomBlochState(r *utils.SeededRand) qubit.BlochState {
 	theta := (0.2 * math.Pi) + r.Float64()*(0.6*math.Pi) // избегаем полюсов для наглядности
 	phi := r.Float64() * 2 * math.Pi
-	return qubit.BlochState{Theta: theta, Phi: phi}
+	return qubit.BlochState{Theta: theta, Phi: phi, Radius: 1}
 }
 
-func equatorBloch(phi float64) qubit.BlochState {
-	normalized := math.Mod(phi, 2*math.Pi)
-	if normalized < 0 {
-		normalized += 2 * math.Pi
+// reloadLocked fetches a session again after a failed save. The store has put
+// its last written version back, so the pointer the caller held is stale, and
+// the restored copy learns who is connected from the open sockets.
+func (s *TeleportationService) reloadLocked(id string) (*teleportation.SessionState, bool) {
+	session, ok := s.sessions.Get(id)
+	if !ok {
+		return nil, false
+	}
+	for role, p := range session.Participants {
+		p.Connected = false
+		for _, entry := range s.listeners[id] {
+			p.Connected = p.Connected || entry.role == role
+		}
+		session.Participants[role] = p
 	}
-	return qubit.BlochState{Theta: math.Pi / 2, Phi: normalized}
+	return session, true
+}
+
+// saveLocked stamps the session activity and persists it.
+func (s *TeleportationService) saveLocked(session *teleportation.SessionState) error {
+	session.UpdatedAt = time.Now()
+	return s.sessions.Save(session)
 }
 
-func collapseBloch(initial qubit.BlochState) qubit.BlochState {
-	if math.Cos(initial.Theta) >= 0 {
-		return qubit.BlochState{Theta: 0, Phi: 0}
+// applyNoiseLocked runs the session's noise channel on every simulated qubit.
+func applyNoiseLocked(session *teleportation.SessionState) {
+	noise := session.Config.Noise
+	density, ok := session.Register.(*quantum.DensityMatrix)
+	if noise == nil || !ok {
+		return
+	}
+	ops, err := quantum.Kraus(noise.Channel, noise.Probability)
+	if err != nil {
+		return
+	}
+	for q := 0; q < density.Qubits(); q++ {
+		density.ApplyKraus(ops, q)
 	}
-	return qubit.BlochState{Theta: math.Pi, Phi: 0}
+}
+
+// stepReached reports whether the session has entered the given step.
+func stepReached(session *teleportation.SessionState, step teleportation.Step) bool {
+	for i := 0; i <= session.StepIndex && i < len(session.Steps); i++ {
+		if session.Steps[i].Key == step {
+			return true
+		}
+	}
+	return false
 }
 
 // broadcastLocked sends the current session state to all listeners with scoped local data.
 func (s *TeleportationService) broadcastLocked(session *teleportation.SessionState) {
+	protocol := s.protocols[session.Config.Protocol]
 	conns := s.listeners[session.ID]
 	for conn, entry := range conns {
+		view := projectLocked(protocol, session, entry.role)
 		local := LocalView{Role: entry.role}
 		for _, qb := range session.Qubits {
 			if qb.Role == entry.role {
 				local.State = qb.State
 			}
 		}
+		if entry.role != qubit.RoleObserver {
+			local.Measurement = view.Measurement
+		}
+		entry.mu.Lock()
+		_ = conn.WriteJSON(BroadcastMessage{Type: "state_update", Global: view, Local: local})
+		entry.mu.Unlock()
+	}
+}
+
+// infoLocked pushes an info event to every listener of the session except skip.
+func (s *TeleportationService) infoLocked(session *teleportation.SessionState, info InfoEvent, skip *websocket.Conn) {
+	for conn, entry := range s.listeners[session.ID] {
+		if conn == skip {
+			continue
+		}
 		entry.mu.Lock()
-		_ = conn.WriteJSON(BroadcastMessage{Type: "state_update", Global: session, Local: local})
+		_ = conn.WriteJSON(BroadcastMessage{Type: "info", Info: &info})
 		entry.mu.Unlock()
 	}
 }
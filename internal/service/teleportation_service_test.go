@@ -1,11 +1,14 @@
 package service
 
 import (
+	"math"
 	"strings"
 	"testing"
 	"time"
 
+	"quantum-teleport/internal/domain/quantum"
 	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
 )
 
 func TestCreateSessionInitialState(t *testing.T) {
@@ -132,6 +135,13 @@ func TestAdvanceStepHonorsRolePermissions(t *testing.T) {
 		t.Fatalf("expected reconstruct step after send, got %d", sendStep.StepIndex)
 	}
 
+	if _, err := service.AdvanceStep(session.ID, bob.Token); err == nil {
+		t.Fatal("expected bob to be blocked until a correction is applied")
+	}
+	if _, err := service.ApplyCorrection(session.ID, bob.Token, sendStep.Measurement.Correction); err != nil {
+		t.Fatalf("expected bob to apply correction, got %v", err)
+	}
+
 	finalState, err := service.AdvanceStep(session.ID, bob.Token)
 	if err != nil {
 		t.Fatalf("expected bob to complete reconstruction, got %v", err)
@@ -150,9 +160,10 @@ func TestLeaveSessionReleasesRole(t *testing.T) {
 
 	participant, _ := service.JoinSession(session.ID, qubit.RoleBob, "")
 
-	beforeLeave := service.sessions[session.ID].Participants[qubit.RoleBob]
+	stored, _ := service.sessions.Get(session.ID)
+	beforeLeave := stored.Participants[qubit.RoleBob]
 	beforeLeave.LastSeen = time.Now().Add(-time.Hour)
-	service.sessions[session.ID].Participants[qubit.RoleBob] = beforeLeave
+	stored.Participants[qubit.RoleBob] = beforeLeave
 
 	updated, err := service.LeaveSession(session.ID, participant.Token)
 	if err != nil {
@@ -167,3 +178,208 @@ func TestLeaveSessionReleasesRole(t *testing.T) {
 		t.Fatalf("expected release event to be logged, log: %v", updated.Log)
 	}
 }
+
+func TestAdvanceStepTeleportsHiddenStateToBob(t *testing.T) {
+	service := NewTeleportationService()
+	session, _ := service.CreateSession()
+
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+	bob, _ := service.JoinSession(session.ID, qubit.RoleBob, "")
+
+	tokens := []string{alice.Token, alice.Token, alice.Token, bob.Token}
+	var current *teleportation.SessionState
+	for _, token := range tokens {
+		var err error
+		if current, err = service.AdvanceStep(session.ID, token); err != nil {
+			t.Fatalf("expected advance to succeed, got %v", err)
+		}
+	}
+	if _, err := service.ApplyCorrection(session.ID, bob.Token, current.Measurement.Correction); err != nil {
+		t.Fatalf("expected correction to succeed, got %v", err)
+	}
+	final, err := service.AdvanceStep(session.ID, bob.Token)
+	if err != nil {
+		t.Fatalf("expected advance to succeed, got %v", err)
+	}
+
+	got := final.Qubits[1].Bloch
+	want := final.HiddenState
+	if math.Abs(got.Theta-want.Theta) > 1e-9 || math.Abs(got.Phi-want.Phi) > 1e-9 {
+		t.Fatalf("expected bob to hold %+v, got %+v", want, got)
+	}
+}
+
+func TestMeasureStepRecordsClassicalBits(t *testing.T) {
+	service := NewTeleportationService()
+	session, _ := service.CreateSession()
+
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+	for i := 0; i < 2; i++ {
+		if _, err := service.AdvanceStep(session.ID, alice.Token); err != nil {
+			t.Fatalf("expected advance to succeed, got %v", err)
+		}
+	}
+
+	measured, _ := service.GetSession(session.ID)
+	if measured.Measurement == nil {
+		t.Fatal("expected measurement to be recorded")
+	}
+	m := measured.Measurement
+	if m.M1 < 0 || m.M1 > 1 || m.M2 < 0 || m.M2 > 1 {
+		t.Fatalf("expected binary outcome, got %+v", m)
+	}
+	if math.Abs(m.Probability-0.25) > 1e-9 {
+		t.Fatalf("expected every bell outcome to have probability 1/4, got %f", m.Probability)
+	}
+	if m.Outcome == "" || m.Correction == "" {
+		t.Fatalf("expected outcome and corrections to be named, got %+v", m)
+	}
+}
+
+func TestApplyCorrectionScoresWrongChoiceAndAllowsRetry(t *testing.T) {
+	service := NewTeleportationService()
+	session, _ := service.CreateSession()
+
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+	bob, _ := service.JoinSession(session.ID, qubit.RoleBob, "")
+	for _, token := range []string{alice.Token, alice.Token, alice.Token} {
+		if _, err := service.AdvanceStep(session.ID, token); err != nil {
+			t.Fatalf("expected advance to succeed, got %v", err)
+		}
+	}
+
+	if _, err := service.ApplyCorrection(session.ID, bob.Token, teleportation.CorrectionX); err == nil {
+		t.Fatal("expected correction to be rejected before reconstruct step")
+	}
+	current, err := service.AdvanceStep(session.ID, bob.Token)
+	if err != nil {
+		t.Fatalf("expected bob to advance send step, got %v", err)
+	}
+	if _, err := service.ApplyCorrection(session.ID, alice.Token, teleportation.CorrectionX); err == nil {
+		t.Fatal("expected alice to be blocked from correcting")
+	}
+	if _, err := service.ApplyCorrection(session.ID, bob.Token, "Y"); err == nil {
+		t.Fatal("expected unknown correction to be rejected")
+	}
+
+	required := current.Measurement.Correction
+	wrong := teleportation.CorrectionX
+	if required == teleportation.CorrectionX {
+		wrong = teleportation.CorrectionZ
+	}
+
+	failed, err := service.ApplyCorrection(session.ID, bob.Token, wrong)
+	if err != nil {
+		t.Fatalf("expected wrong correction to be accepted, got %v", err)
+	}
+	if failed.Correction.Correct || failed.Correction.Attempts != 1 {
+		t.Fatalf("expected a failed first attempt, got %+v", failed.Correction)
+	}
+
+	retried, err := service.ApplyCorrection(session.ID, bob.Token, required)
+	if err != nil {
+		t.Fatalf("expected retry to be accepted, got %v", err)
+	}
+	if !retried.Correction.Correct || retried.Correction.Attempts != 2 {
+		t.Fatalf("expected a correct second attempt, got %+v", retried.Correction)
+	}
+	if math.Abs(retried.Correction.Fidelity-1) > 1e-9 {
+		t.Fatalf("expected perfect fidelity after retry, got %f", retried.Correction.Fidelity)
+	}
+}
+
+func TestNoisySessionLowersTeleportationFidelity(t *testing.T) {
+	service := NewTeleportationService()
+	noise := &teleportation.NoiseProfile{Channel: quantum.ChannelDepolarizing, Probability: 0.1}
+	session, err := service.CreateSessionWithConfig(teleportation.Config{Noise: noise})
+	if err != nil {
+		t.Fatalf("expected noisy session, got %v", err)
+	}
+
+	alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+	bob, _ := service.JoinSession(session.ID, qubit.RoleBob, "")
+	var current *teleportation.SessionState
+	for _, token := range []string{alice.Token, alice.Token, alice.Token, bob.Token} {
+		if current, err = service.AdvanceStep(session.ID, token); err != nil {
+			t.Fatalf("expected advance to succeed, got %v", err)
+		}
+	}
+
+	corrected, err := service.ApplyCorrection(session.ID, bob.Token, current.Measurement.Correction)
+	if err != nil {
+		t.Fatalf("expected correction to succeed, got %v", err)
+	}
+	if f := corrected.Correction.Fidelity; f >= 1-1e-6 || f <= 0.5 {
+		t.Fatalf("expected noisy fidelity strictly between 0.5 and 1, got %f", f)
+	}
+	if r := corrected.Qubits[1].Bloch.Radius; r >= 1-1e-6 {
+		t.Fatalf("expected bob's qubit to be mixed, got radius %f", r)
+	}
+}
+
+func TestCreateSessionRejectsInvalidNoise(t *testing.T) {
+	service := NewTeleportationService()
+	noise := &teleportation.NoiseProfile{Channel: quantum.ChannelDephasing, Probability: -0.1}
+	if _, err := service.CreateSessionWithConfig(teleportation.Config{Noise: noise}); err == nil {
+		t.Fatal("expected negative noise probability to be rejected")
+	}
+}
+
+func TestSeededServiceReplaysSessionsExactly(t *testing.T) {
+	run := func() *teleportation.SessionState {
+		service := NewTeleportationService(WithSeed(7))
+		session, _ := service.CreateSession()
+		alice, _ := service.JoinSession(session.ID, qubit.RoleAlice, "")
+		for i := 0; i < 2; i++ {
+			if _, err := service.AdvanceStep(session.ID, alice.Token); err != nil {
+				t.Fatalf("expected advance to succeed, got %v", err)
+			}
+		}
+		return session
+	}
+
+	first, second := run(), run()
+	if first.Config.Seed == nil || *first.Config.Seed != *second.Config.Seed {
+		t.Fatalf("expected the session seed to be recorded and equal, got %v and %v", first.Config.Seed, second.Config.Seed)
+	}
+	if first.HiddenState != second.HiddenState {
+		t.Fatalf("expected equal initial states, got %+v and %+v", first.HiddenState, second.HiddenState)
+	}
+	if *first.Measurement != *second.Measurement {
+		t.Fatalf("expected equal measurement outcomes, got %+v and %+v", first.Measurement, second.Measurement)
+	}
+}
+
+func TestObserversWatchWithoutActing(t *testing.T) {
+	service := NewTeleportationService()
+	session, _ := service.CreateSession()
+
+	first, err := service.ObserveSession(session.ID)
+	if err != nil {
+		t.Fatalf("expected observer to be admitted, got %v", err)
+	}
+	second, _ := service.ObserveSession(session.ID)
+	if first.Token == "" || first.Token == second.Token {
+		t.Fatalf("expected distinct observer tokens, got %q and %q", first.Token, second.Token)
+	}
+	if session.ObserverCount != 2 {
+		t.Fatalf("expected 2 observers, got %d", session.ObserverCount)
+	}
+
+	if _, err := service.AdvanceStep(session.ID, first.Token); err == nil || !strings.Contains(err.Error(), "read-only") {
+		t.Fatalf("expected observer advance to be rejected as read-only, got %v", err)
+	}
+	if err := service.Annotate(session.ID, first.Token, "привет"); err == nil {
+		t.Fatal("expected observer annotation to be rejected")
+	}
+	if _, err := service.JoinSession(session.ID, qubit.RoleObserver, ""); err == nil {
+		t.Fatal("expected observer role to be unavailable through join")
+	}
+
+	if _, err := service.LeaveSession(session.ID, first.Token); err != nil {
+		t.Fatalf("expected observer to leave, got %v", err)
+	}
+	if session.ObserverCount != 1 {
+		t.Fatalf("expected 1 observer after leave, got %d", session.ObserverCount)
+	}
+}
@@ -2,18 +2,29 @@ package http
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
 	"strings"
 
+	"quantum-teleport/internal/domain/quantum"
 	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
 	"quantum-teleport/internal/service"
 )
 
+// Request bodies are capped; an import carries a whole event log and gets more room.
+const (
+	maxBodyBytes   = 64 << 10
+	maxImportBytes = 4 << 20
+)
+
 // Router registers HTTP handlers for REST endpoints.
 type Router struct {
 	service *service.TeleportationService
 	logger  *slog.Logger
+	replay  func(http.ResponseWriter, *http.Request, string)
 }
 
 // NewRouter constructs a router with provided service.
@@ -21,6 +32,12 @@ func NewRouter(service *service.TeleportationService, logger *slog.Logger) *Rout
 	return &Router{service: service, logger: logger}
 }
 
+// HandleReplay serves GET /api/sessions/{id}/replay with serve. The replay is
+// a WebSocket stream, so the handler lives in the ws transport.
+func (r *Router) HandleReplay(serve func(w http.ResponseWriter, req *http.Request, id string)) {
+	r.replay = serve
+}
+
 // Register attaches handlers to the given ServeMux.
 func (r *Router) Register(mux *http.ServeMux) {
 	mux.HandleFunc("/healthz", r.handleHealth)
@@ -36,6 +53,7 @@ func (r *Router) handleHealth(w http.ResponseWriter, _ *http.Request) {
 func (r *Router) handleSessions(w http.ResponseWriter, req *http.Request) {
 	switch req.Method {
 	case http.MethodPost:
+		req.Body = http.MaxBytesReader(w, req.Body, maxBodyBytes)
 		r.createSession(w, req)
 	default:
 		w.WriteHeader(http.StatusMethodNotAllowed)
@@ -51,15 +69,45 @@ func (r *Router) handleSessionByID(w http.ResponseWriter, req *http.Request) {
 
 	switch req.Method {
 	case http.MethodGet:
-		r.getSession(w, req, id)
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/events"):
+			r.sessionEvents(w, req, strings.TrimSuffix(id, "/events"))
+		case strings.HasSuffix(req.URL.Path, "/export"):
+			r.exportSession(w, req, strings.TrimSuffix(id, "/export"))
+		case strings.HasSuffix(req.URL.Path, "/circuit"):
+			r.sessionCircuit(w, req, strings.TrimSuffix(id, "/circuit"))
+		case strings.HasSuffix(req.URL.Path, "/replay"):
+			r.replaySession(w, req, strings.TrimSuffix(id, "/replay"))
+		default:
+			r.getSession(w, req, id)
+		}
 	case http.MethodPost:
+		limit := int64(maxBodyBytes)
+		if id == "import" {
+			limit = maxImportBytes
+		}
+		req.Body = http.MaxBytesReader(w, req.Body, limit)
 		switch {
+		case id == "import":
+			r.importSession(w, req)
 		case strings.HasSuffix(req.URL.Path, "/advance"):
 			r.advanceSession(w, req, strings.TrimSuffix(id, "/advance"))
 		case strings.HasSuffix(req.URL.Path, "/join"):
 			r.joinSession(w, req, strings.TrimSuffix(id, "/join"))
+		case strings.HasSuffix(req.URL.Path, "/observe"):
+			r.observeSession(w, req, strings.TrimSuffix(id, "/observe"))
 		case strings.HasSuffix(req.URL.Path, "/leave"):
 			r.leaveSession(w, req, strings.TrimSuffix(id, "/leave"))
+		case strings.HasSuffix(req.URL.Path, "/correct"):
+			r.correctSession(w, req, strings.TrimSuffix(id, "/correct"))
+		case strings.HasSuffix(req.URL.Path, "/encode"):
+			r.encodeSession(w, req, strings.TrimSuffix(id, "/encode"))
+		case strings.HasSuffix(req.URL.Path, "/tamper"):
+			r.tamperSession(w, req, strings.TrimSuffix(id, "/tamper"))
+		case strings.HasSuffix(req.URL.Path, "/rewind"):
+			r.rewindSession(w, req, strings.TrimSuffix(id, "/rewind"))
+		case strings.HasSuffix(req.URL.Path, "/host"):
+			r.hostSession(w, req, strings.TrimSuffix(id, "/host"))
 		default:
 			w.WriteHeader(http.StatusNotFound)
 		}
@@ -68,14 +116,121 @@ func (r *Router) handleSessionByID(w http.ResponseWriter, req *http.Request) {
 	}
 }
 
-func (r *Router) createSession(w http.ResponseWriter, _ *http.Request) {
-	session, err := r.service.CreateSession()
+type amplitude struct {
+	Re float64 `json:"re"`
+	Im float64 `json:"im"`
+}
+
+type noiseOptions struct {
+	Channel     string  `json:"channel"`
+	Probability float64 `json:"probability"`
+}
+
+type createRequest struct {
+	Preset   string        `json:"preset"`
+	Theta    *float64      `json:"theta"`
+	Phi      *float64      `json:"phi"`
+	Alpha    *amplitude    `json:"alpha"`
+	Beta     *amplitude    `json:"beta"`
+	Seed     *int64        `json:"seed"`
+	Noise    *noiseOptions `json:"noise"`
+	Protocol string        `json:"protocol"`
+	Relays   int           `json:"relays"`
+	Qubits   int           `json:"qubits"`
+	Eve      bool          `json:"eavesdropper"`
+}
+
+// config validates the options payload and maps it onto a session config.
+func (c createRequest) config() (teleportation.Config, error) {
+	config := teleportation.Config{
+		Protocol:     strings.ToLower(c.Protocol),
+		Seed:         c.Seed,
+		Relays:       c.Relays,
+		Qubits:       c.Qubits,
+		Eavesdropper: c.Eve,
+	}
+
+	sources := 0
+	if c.Preset != "" {
+		sources++
+	}
+	if c.Theta != nil || c.Phi != nil {
+		sources++
+	}
+	if c.Alpha != nil || c.Beta != nil {
+		sources++
+	}
+	if sources > 1 {
+		return config, errors.New("initial state must be given by only one of preset, theta/phi or alpha/beta")
+	}
+
+	switch {
+	case c.Preset != "":
+		state, ok := qubit.Preset(c.Preset)
+		if !ok {
+			return config, errors.New("unknown preset")
+		}
+		config.InitialState = &state
+	case c.Theta != nil || c.Phi != nil:
+		if c.Theta == nil || c.Phi == nil {
+			return config, errors.New("theta and phi must be given together")
+		}
+		state, err := qubit.NewBlochState(*c.Theta, *c.Phi)
+		if err != nil {
+			return config, err
+		}
+		config.InitialState = &state
+	case c.Alpha != nil || c.Beta != nil:
+		if c.Alpha == nil || c.Beta == nil {
+			return config, errors.New("alpha and beta must be given together")
+		}
+		state, err := qubit.BlochFromAmplitudes(complex(c.Alpha.Re, c.Alpha.Im), complex(c.Beta.Re, c.Beta.Im))
+		if err != nil {
+			return config, err
+		}
+		config.InitialState = &state
+	}
+
+	if c.Noise != nil {
+		config.Noise = &teleportation.NoiseProfile{
+			Channel:     quantum.Channel(strings.ToLower(c.Noise.Channel)),
+			Probability: c.Noise.Probability,
+		}
+	}
+	return config, nil
+}
+
+func (r *Router) createSession(w http.ResponseWriter, req *http.Request) {
+	var body createRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil && !errors.Is(err, io.EOF) {
+		payloadError(w, err)
+		return
+	}
+	config, err := body.config()
 	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	session, err := r.service.CreateSessionWithConfig(config)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err.Error() {
+		case "unsupported protocol", "invalid initial state", "initial state not supported", "invalid noise profile", "invalid chain length", "invalid qubit count",
+			"relays not supported", "qubit count not supported", "eavesdropper not supported":
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
 		return
 	}
 	r.logger.Info("session created", slog.String("session", session.ID))
-	writeJSON(w, session)
+	r.writeCreated(w, session.ID, session.HostToken)
+}
+
+// createResponse is the full session view plus the host token, which is
+// returned only once, to whoever created the session.
+type createResponse struct {
+	*teleportation.SessionState
+	HostToken string `json:"hostToken"`
 }
 
 type joinRequest struct {
@@ -97,8 +252,11 @@ func (r *Router) joinSession(w http.ResponseWriter, req *http.Request, id string
 	participant, err := r.service.JoinSession(id, qubit.Role(strings.ToLower(body.Role)), body.Token)
 	if err != nil {
 		status := http.StatusConflict
-		if err.Error() == "session not found" {
+		switch err.Error() {
+		case "session not found":
 			status = http.StatusNotFound
+		case "session locked":
+			status = http.StatusLocked
 		}
 		http.Error(w, err.Error(), status)
 		return
@@ -107,9 +265,27 @@ func (r *Router) joinSession(w http.ResponseWriter, req *http.Request, id string
 	writeJSON(w, joinResponse{Token: participant.Token, Role: string(participant.Role)})
 }
 
-func (r *Router) getSession(w http.ResponseWriter, _ *http.Request, id string) {
-	session, err := r.service.GetSession(id)
+func (r *Router) observeSession(w http.ResponseWriter, _ *http.Request, id string) {
+	observer, err := r.service.ObserveSession(id)
 	if err != nil {
+		status := http.StatusInternalServerError
+		if err.Error() == "session not found" {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	r.logger.Info("observer joined", slog.String("session", id))
+	writeJSON(w, joinResponse{Token: observer.Token, Role: string(qubit.RoleObserver)})
+}
+
+func (r *Router) getSession(w http.ResponseWriter, req *http.Request, id string) {
+	session, err := r.service.SessionView(id, req.URL.Query().Get("token"))
+	if err != nil {
+		if err.Error() != "session not found" {
+			http.Error(w, err.Error(), http.StatusForbidden)
+			return
+		}
 		r.logger.Warn("session not found", slog.String("session", id))
 		http.Error(w, "session not found", http.StatusNotFound)
 		return
@@ -118,6 +294,135 @@ func (r *Router) getSession(w http.ResponseWriter, _ *http.Request, id string) {
 	writeJSON(w, session)
 }
 
+func (r *Router) sessionEvents(w http.ResponseWriter, req *http.Request, id string) {
+	events, err := r.service.Events(id, req.URL.Query().Get("token"))
+	if err != nil {
+		status := http.StatusForbidden
+		if err.Error() == "session not found" {
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	writeJSON(w, events)
+}
+
+// sessionCircuit renders the session circuit in the requested format:
+// OpenQASM 3 by default, or the circuit model as JSON.
+func (r *Router) sessionCircuit(w http.ResponseWriter, req *http.Request, id string) {
+	c, err := r.service.Circuit(id, req.URL.Query().Get("token"))
+	if err != nil {
+		status := http.StatusForbidden
+		switch err.Error() {
+		case "session not found", "circuit unsupported for protocol":
+			status = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	switch strings.ToLower(req.URL.Query().Get("format")) {
+	case "", "qasm":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte(c.QASM()))
+	case "json":
+		writeJSON(w, c)
+	case "tex":
+		w.Header().Set("Content-Type", "application/x-tex; charset=utf-8")
+		_, _ = w.Write([]byte(c.Quantikz()))
+	case "svg":
+		w.Header().Set("Content-Type", "image/svg+xml")
+		_, _ = w.Write([]byte(c.SVG()))
+	default:
+		http.Error(w, "unsupported format", http.StatusBadRequest)
+	}
+}
+
+func (r *Router) replaySession(w http.ResponseWriter, req *http.Request, id string) {
+	if r.replay == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	r.replay(w, req, id)
+}
+
+func (r *Router) exportSession(w http.ResponseWriter, req *http.Request, id string) {
+	bundle, err := r.service.ExportSession(id, req.URL.Query().Get("token"))
+	if err != nil {
+		status := http.StatusForbidden
+		switch err.Error() {
+		case "session not found":
+			status = http.StatusNotFound
+		case "session has no history":
+			status = http.StatusConflict
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	r.logger.Info("session exported", slog.String("session", id), slog.Int("events", len(bundle.Events)))
+	w.Header().Set("Content-Disposition", `attachment; filename="session-`+id+`.json"`)
+	writeJSON(w, bundle)
+}
+
+func (r *Router) importSession(w http.ResponseWriter, req *http.Request) {
+	var bundle service.ExportBundle
+	if err := json.NewDecoder(req.Body).Decode(&bundle); err != nil {
+		payloadError(w, err)
+		return
+	}
+	session, err := r.service.ImportSession(bundle)
+	if err != nil {
+		status := http.StatusInternalServerError
+		switch err.Error() {
+		case "unsupported export version", "config seed does not match bundle seed", "events were recorded with another seed",
+			"unsupported protocol", "invalid initial state", "initial state not supported", "invalid noise profile", "invalid chain length",
+			"invalid qubit count", "relays not supported", "qubit count not supported", "eavesdropper not supported",
+			"too many events", "config does not match events", "initial state does not match events":
+			status = http.StatusBadRequest
+		default:
+			if strings.HasPrefix(err.Error(), "invalid events: ") {
+				status = http.StatusBadRequest
+			}
+		}
+		r.logger.Warn("session import failed", slog.String("error", err.Error()))
+		http.Error(w, err.Error(), status)
+		return
+	}
+	r.logger.Info("session imported", slog.String("session", session.ID), slog.Int("events", len(bundle.Events)))
+	r.writeCreated(w, session.ID, session.HostToken)
+}
+
+// payloadError answers a body that failed to decode: 413 when it ran past its
+// cap, 400 otherwise.
+func payloadError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, "payload too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, "invalid payload", http.StatusBadRequest)
+}
+
+// writeCreated answers with the host's view of a new session and its host
+// token. The view is a copy, so encoding it cannot race with a socket joining.
+func (r *Router) writeCreated(w http.ResponseWriter, id string, hostToken string) {
+	view, err := r.service.SessionView(id, hostToken)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, createResponse{SessionState: view, HostToken: hostToken})
+}
+
+// writeView answers with the session as seen by the holder of token.
+func (r *Router) writeView(w http.ResponseWriter, id string, token string) {
+	view, err := r.service.SessionView(id, token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	writeJSON(w, view)
+}
+
 type advanceRequest struct {
 	Token string `json:"token"`
 }
@@ -135,7 +440,7 @@ func (r *Router) advanceSession(w http.ResponseWriter, req *http.Request, id str
 		return
 	}
 	r.logger.Info("session advanced", slog.String("session", id), slog.Int("step", session.StepIndex))
-	writeJSON(w, session)
+	r.writeView(w, id, body.Token)
 }
 
 type leaveRequest struct {
@@ -148,7 +453,7 @@ func (r *Router) leaveSession(w http.ResponseWriter, req *http.Request, id strin
 		http.Error(w, "invalid payload", http.StatusBadRequest)
 		return
 	}
-	session, err := r.service.LeaveSession(id, body.Token)
+	_, err := r.service.LeaveSession(id, body.Token)
 	if err != nil {
 		status := http.StatusForbidden
 		if err.Error() == "session not found" {
@@ -158,7 +463,143 @@ func (r *Router) leaveSession(w http.ResponseWriter, req *http.Request, id strin
 		return
 	}
 	r.logger.Info("role left", slog.String("session", id))
-	writeJSON(w, session)
+	r.writeView(w, id, "")
+}
+
+type correctRequest struct {
+	Token      string `json:"token"`
+	Correction string `json:"correction"`
+}
+
+func (r *Router) correctSession(w http.ResponseWriter, req *http.Request, id string) {
+	var body correctRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.Token == "" {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	session, err := r.service.ApplyCorrection(id, body.Token, teleportation.Correction(strings.ToUpper(body.Correction)))
+	if err != nil {
+		status := http.StatusForbidden
+		switch err.Error() {
+		case "session not found":
+			status = http.StatusNotFound
+		case "invalid correction":
+			status = http.StatusBadRequest
+		}
+		r.logger.Warn("correction failed", slog.String("session", id), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), status)
+		return
+	}
+	r.logger.Info("correction applied", slog.String("session", id), slog.String("correction", string(session.Correction.Applied)))
+	r.writeView(w, id, body.Token)
+}
+
+type encodeRequest struct {
+	Token string `json:"token"`
+	Bits  string `json:"bits"`
+}
+
+func (r *Router) encodeSession(w http.ResponseWriter, req *http.Request, id string) {
+	var body encodeRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.Token == "" {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if _, err := r.service.EncodeBits(id, body.Token, body.Bits); err != nil {
+		status := http.StatusForbidden
+		switch err.Error() {
+		case "session not found":
+			status = http.StatusNotFound
+		case "invalid bits":
+			status = http.StatusBadRequest
+		}
+		r.logger.Warn("encoding failed", slog.String("session", id), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), status)
+		return
+	}
+	r.logger.Info("bits encoded", slog.String("session", id))
+	r.writeView(w, id, body.Token)
+}
+
+type tamperRequest struct {
+	Token string `json:"token"`
+	Flip  string `json:"flip"`
+}
+
+func (r *Router) tamperSession(w http.ResponseWriter, req *http.Request, id string) {
+	var body tamperRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.Token == "" {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	if _, err := r.service.TamperBits(id, body.Token, body.Flip); err != nil {
+		status := http.StatusForbidden
+		switch err.Error() {
+		case "session not found":
+			status = http.StatusNotFound
+		case "invalid flip":
+			status = http.StatusBadRequest
+		}
+		r.logger.Warn("tampering failed", slog.String("session", id), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), status)
+		return
+	}
+	r.logger.Info("bits tampered", slog.String("session", id))
+	r.writeView(w, id, body.Token)
+}
+
+type hostRequest struct {
+	Token  string `json:"token"`
+	Action string `json:"action"`
+	Step   int    `json:"step"`
+	Role   string `json:"role"`
+}
+
+func (r *Router) hostSession(w http.ResponseWriter, req *http.Request, id string) {
+	var body hostRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.Token == "" {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	r.runHostCommand(w, id, body.Token, service.HostCommand{
+		Action: service.HostAction(strings.ToLower(body.Action)),
+		Step:   body.Step,
+		Role:   qubit.Role(strings.ToLower(body.Role)),
+	})
+}
+
+type rewindRequest struct {
+	Token string `json:"token"`
+	Step  *int   `json:"step"`
+}
+
+func (r *Router) rewindSession(w http.ResponseWriter, req *http.Request, id string) {
+	var body rewindRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil || body.Token == "" || body.Step == nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+	r.runHostCommand(w, id, body.Token, service.HostCommand{Action: service.HostRewind, Step: *body.Step})
+}
+
+// runHostCommand applies a host command and answers with the instructor's view.
+func (r *Router) runHostCommand(w http.ResponseWriter, id string, token string, cmd service.HostCommand) {
+	if _, err := r.service.HostControl(id, token, cmd); err != nil {
+		status := http.StatusForbidden
+		switch err.Error() {
+		case "session not found":
+			status = http.StatusNotFound
+		case "invalid host action", "invalid step", "no snapshot for step", "role unsupported":
+			status = http.StatusBadRequest
+		case "role not taken":
+			status = http.StatusConflict
+		}
+		r.logger.Warn("host action failed", slog.String("session", id), slog.String("action", string(cmd.Action)), slog.String("error", err.Error()))
+		http.Error(w, err.Error(), status)
+		return
+	}
+	r.logger.Info("host action applied", slog.String("session", id), slog.String("action", string(cmd.Action)))
+	r.writeView(w, id, token)
 }
 
 func writeJSON(w http.ResponseWriter, payload any) {
@@ -4,12 +4,15 @@ import (
 	"bytes"
 	"encoding/json"
 	"io"
+	"math"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"log/slog"
 
+	"quantum-teleport/internal/domain/qubit"
 	"quantum-teleport/internal/domain/teleportation"
 	"quantum-teleport/internal/service"
 )
@@ -44,6 +47,10 @@ func TestRouterTeleportationFlow(t *testing.T) {
 	}
 
 	session = advanceSession(t, server.URL, session.ID, bobToken)
+	session = correctSession(t, server.URL, session.ID, bobToken, string(session.Measurement.Correction))
+	if session.Correction == nil || !session.Correction.Correct {
+		t.Fatalf("expected correct correction to be recorded, got %+v", session.Correction)
+	}
 	session = advanceSession(t, server.URL, session.ID, bobToken)
 	if session.StepIndex != len(session.Steps)-1 {
 		t.Fatalf("expected final step reached via HTTP flow, got %d", session.StepIndex)
@@ -121,6 +128,29 @@ func advanceSession(t *testing.T, baseURL, sessionID, token string) teleportatio
 	return session
 }
 
+func correctSession(t *testing.T, baseURL, sessionID, token, correction string) teleportation.SessionState {
+	t.Helper()
+
+	body := map[string]string{"token": token, "correction": correction}
+	payload, _ := json.Marshal(body)
+
+	resp, err := http.Post(baseURL+"/api/sessions/"+sessionID+"/correct", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("failed to apply correction: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected correct status 200, got %d", resp.StatusCode)
+	}
+
+	var session teleportation.SessionState
+	if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+		t.Fatalf("failed to decode corrected session: %v", err)
+	}
+	return session
+}
+
 func getSessionRequest(t *testing.T, baseURL, sessionID string) teleportation.SessionState {
 	t.Helper()
 
@@ -140,3 +170,666 @@ func getSessionRequest(t *testing.T, baseURL, sessionID string) teleportation.Se
 	}
 	return session
 }
+
+func TestRouterCreateSessionOptions(t *testing.T) {
+	svc := service.NewTeleportationService()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mux := http.NewServeMux()
+	NewRouter(svc, logger).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	plus := createSessionWithOptions(t, server.URL, `{"preset":"|+>","noise":{"channel":"dephasing","probability":0.05}}`, http.StatusOK)
+	if plus.Config.InitialState == nil || math.Abs(plus.Config.InitialState.Theta-math.Pi/2) > 1e-9 {
+		t.Fatalf("expected |+> preset to be applied, got %+v", plus.Config.InitialState)
+	}
+	if plus.Config.Noise == nil || plus.Config.Noise.Channel != "dephasing" {
+		t.Fatalf("expected noise profile to be reflected, got %+v", plus.Config.Noise)
+	}
+
+	amplitudes := createSessionWithOptions(t, server.URL, `{"alpha":{"re":0,"im":0},"beta":{"re":0,"im":2}}`, http.StatusOK)
+	if math.Abs(amplitudes.Config.InitialState.Theta-math.Pi) > 1e-9 {
+		t.Fatalf("expected beta-only amplitudes to give |1>, got %+v", amplitudes.Config.InitialState)
+	}
+
+	first := createSessionWithOptions(t, server.URL, `{"seed":42}`, http.StatusOK)
+	second := createSessionWithOptions(t, server.URL, `{"seed":42}`, http.StatusOK)
+	if first.Qubits[0].Bloch != second.Qubits[0].Bloch {
+		t.Fatalf("expected equal seeds to produce equal states, got %+v and %+v", first.Qubits[0].Bloch, second.Qubits[0].Bloch)
+	}
+
+	createSessionWithOptions(t, server.URL, `{"preset":"0","theta":1,"phi":0}`, http.StatusBadRequest)
+	createSessionWithOptions(t, server.URL, `{"theta":4,"phi":0}`, http.StatusBadRequest)
+	createSessionWithOptions(t, server.URL, `{"preset":"|2>"}`, http.StatusBadRequest)
+	createSessionWithOptions(t, server.URL, `{"noise":{"channel":"bitflip","probability":0.1}}`, http.StatusBadRequest)
+	createSessionWithOptions(t, server.URL, `{"protocol":"e91"}`, http.StatusBadRequest)
+	createSessionWithOptions(t, server.URL, `{"protocol":"chain","relays":2,"eavesdropper":true}`, http.StatusBadRequest)
+	createSessionWithOptions(t, server.URL, `{"protocol":"teleportation","relays":3}`, http.StatusBadRequest)
+	createSessionWithOptions(t, server.URL, `{"protocol":"controlled","qubits":8}`, http.StatusBadRequest)
+}
+
+func createSessionWithOptions(t *testing.T, baseURL, options string, expectedStatus int) teleportation.SessionState {
+	t.Helper()
+
+	resp, err := http.Post(baseURL+"/api/sessions", "application/json", strings.NewReader(options))
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedStatus {
+		t.Fatalf("expected status %d for %s, got %d", expectedStatus, options, resp.StatusCode)
+	}
+
+	var session teleportation.SessionState
+	if expectedStatus == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+			t.Fatalf("failed to decode session: %v", err)
+		}
+	}
+	return session
+}
+
+func TestRouterHostControls(t *testing.T) {
+	svc := service.NewTeleportationService()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mux := http.NewServeMux()
+	NewRouter(svc, logger).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resp, err := http.Post(server.URL+"/api/sessions", "application/json", nil)
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	var created struct {
+		ID        string `json:"id"`
+		HostToken string `json:"hostToken"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode session: %v", err)
+	}
+	resp.Body.Close()
+	if created.HostToken == "" {
+		t.Fatal("expected the create response to carry a host token")
+	}
+
+	bobToken := joinRole(t, server.URL, created.ID, "bob", "")
+	advanceSession(t, server.URL, created.ID, bobToken)
+
+	hostAction(t, server.URL, created.ID, map[string]any{"token": bobToken, "action": "reset"}, http.StatusForbidden)
+	hostAction(t, server.URL, created.ID, map[string]any{"token": created.HostToken, "action": "rewind", "step": 4}, http.StatusBadRequest)
+	session := hostAction(t, server.URL, created.ID, map[string]any{"token": created.HostToken, "action": "reset"}, http.StatusOK)
+	if session.StepIndex != 0 {
+		t.Fatalf("expected reset to step 0, got %d", session.StepIndex)
+	}
+
+	hostAction(t, server.URL, created.ID, map[string]any{"token": created.HostToken, "action": "lock"}, http.StatusOK)
+	payload, _ := json.Marshal(map[string]string{"role": "alice"})
+	resp, err = http.Post(server.URL+"/api/sessions/"+created.ID+"/join", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("failed to join: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusLocked {
+		t.Fatalf("expected join to a locked session to return 423, got %d", resp.StatusCode)
+	}
+}
+
+func hostAction(t *testing.T, baseURL, sessionID string, body map[string]any, expectedStatus int) teleportation.SessionState {
+	t.Helper()
+
+	payload, _ := json.Marshal(body)
+	resp, err := http.Post(baseURL+"/api/sessions/"+sessionID+"/host", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("failed to call host action: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != expectedStatus {
+		t.Fatalf("expected host status %d, got %d", expectedStatus, resp.StatusCode)
+	}
+
+	var session teleportation.SessionState
+	if expectedStatus == http.StatusOK {
+		if err := json.NewDecoder(resp.Body).Decode(&session); err != nil {
+			t.Fatalf("failed to decode session: %v", err)
+		}
+	}
+	return session
+}
+
+func TestRouterRewindRequiresHostToken(t *testing.T) {
+	svc := service.NewTeleportationService()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mux := http.NewServeMux()
+	NewRouter(svc, logger).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	session, _ := svc.CreateSession()
+	aliceToken := joinRole(t, server.URL, session.ID, "alice", "")
+	advanceSession(t, server.URL, session.ID, aliceToken)
+	advanceSession(t, server.URL, session.ID, aliceToken)
+
+	rewind := func(token string, step int, expectedStatus int) teleportation.SessionState {
+		t.Helper()
+		payload, _ := json.Marshal(map[string]any{"token": token, "step": step})
+		resp, err := http.Post(server.URL+"/api/sessions/"+session.ID+"/rewind", "application/json", bytes.NewReader(payload))
+		if err != nil {
+			t.Fatalf("failed to rewind: %v", err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != expectedStatus {
+			t.Fatalf("expected rewind status %d, got %d", expectedStatus, resp.StatusCode)
+		}
+		var view teleportation.SessionState
+		_ = json.NewDecoder(resp.Body).Decode(&view)
+		return view
+	}
+
+	rewind(aliceToken, 1, http.StatusForbidden)
+	rewind(session.HostToken, 2, http.StatusBadRequest)
+	view := rewind(session.HostToken, 1, http.StatusOK)
+	if view.StepIndex != 1 || view.Measurement != nil {
+		t.Fatalf("expected the combine step without a measurement, got step %d, %+v", view.StepIndex, view.Measurement)
+	}
+}
+
+func TestRouterSessionEventsForHost(t *testing.T) {
+	svc := service.NewTeleportationService()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mux := http.NewServeMux()
+	NewRouter(svc, logger).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	session, _ := svc.CreateSession()
+	aliceToken := joinRole(t, server.URL, session.ID, "alice", "")
+	advanceSession(t, server.URL, session.ID, aliceToken)
+
+	resp, err := http.Get(server.URL + "/api/sessions/" + session.ID + "/events?token=" + aliceToken)
+	if err != nil {
+		t.Fatalf("failed to fetch events: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusForbidden {
+		t.Fatalf("expected participants to be refused, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Get(server.URL + "/api/sessions/" + session.ID + "/events?token=" + session.HostToken)
+	if err != nil {
+		t.Fatalf("failed to fetch events: %v", err)
+	}
+	defer resp.Body.Close()
+	var events []teleportation.Event
+	if err := json.NewDecoder(resp.Body).Decode(&events); err != nil {
+		t.Fatalf("failed to decode events: %v", err)
+	}
+	expected := []teleportation.EventType{teleportation.EventSessionCreated, teleportation.EventRoleJoined, teleportation.EventStepAdvanced}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %+v", len(expected), events)
+	}
+	for i, e := range events {
+		if e.Type != expected[i] {
+			t.Fatalf("expected event %d to be %s, got %s", i, expected[i], e.Type)
+		}
+	}
+	if events[2].Actor != qubit.RoleAlice || events[2].Payload.Step != 1 {
+		t.Fatalf("expected alice's advance to step 1, got %+v", events[2])
+	}
+}
+
+func TestRouterExportAndImport(t *testing.T) {
+	svc := service.NewTeleportationService()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mux := http.NewServeMux()
+	NewRouter(svc, logger).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	session, _ := svc.CreateSession()
+	aliceToken := joinRole(t, server.URL, session.ID, "alice", "")
+	advanceSession(t, server.URL, session.ID, aliceToken)
+
+	resp, err := http.Get(server.URL + "/api/sessions/" + session.ID + "/export?token=" + session.HostToken)
+	if err != nil {
+		t.Fatalf("failed to export: %v", err)
+	}
+	exported, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected export status 200, got %d", resp.StatusCode)
+	}
+
+	resp, err = http.Post(server.URL+"/api/sessions/import", "application/json", bytes.NewReader(exported))
+	if err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected import status 200, got %d", resp.StatusCode)
+	}
+	var imported struct {
+		ID        string `json:"id"`
+		StepIndex int    `json:"stepIndex"`
+		HostToken string `json:"hostToken"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&imported); err != nil {
+		t.Fatalf("failed to decode import: %v", err)
+	}
+	if imported.ID == session.ID || imported.StepIndex != 1 || imported.HostToken == "" {
+		t.Fatalf("expected a new session at the recorded step, got %+v", imported)
+	}
+
+	resp, err = http.Post(server.URL+"/api/sessions/import", "application/json", strings.NewReader(`{"version":9}`))
+	if err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected unsupported versions to be rejected, got %d", resp.StatusCode)
+	}
+
+	var bundle service.ExportBundle
+	if err := json.Unmarshal(exported, &bundle); err != nil {
+		t.Fatalf("failed to decode export: %v", err)
+	}
+	for step := 2; step < len(session.Steps); step++ {
+		bundle.Events = append(bundle.Events, teleportation.Event{
+			Type:    teleportation.EventStepAdvanced,
+			Payload: teleportation.EventPayload{Step: step},
+		})
+	}
+	skipped, _ := json.Marshal(bundle)
+	resp, err = http.Post(server.URL+"/api/sessions/import", "application/json", bytes.NewReader(skipped))
+	if err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expected steps past a missing correction to be rejected, got %d", resp.StatusCode)
+	}
+}
+
+func TestRouterCircuitAsQASM(t *testing.T) {
+	svc := service.NewTeleportationService()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mux := http.NewServeMux()
+	NewRouter(svc, logger).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	session, hostToken := createSessionWithOptionsAndHost(t, server.URL, `{"preset":"1"}`)
+
+	public := fetchCircuit(t, server.URL+"/api/sessions/"+session+"/circuit", http.StatusOK)
+	if !strings.HasPrefix(public, "OPENQASM 3.0;") || !strings.Contains(public, "U(theta, phi, -phi) q[0];") {
+		t.Fatalf("expected a public QASM program, got\n%s", public)
+	}
+	host := fetchCircuit(t, server.URL+"/api/sessions/"+session+"/circuit?format=qasm&token="+hostToken, http.StatusOK)
+	if !strings.Contains(host, "U(3.141592653589793, 0, 0) q[0];") {
+		t.Fatalf("expected the host to see the prepared |1>, got\n%s", host)
+	}
+	fetchCircuit(t, server.URL+"/api/sessions/"+session+"/circuit?format=pdf", http.StatusBadRequest)
+}
+
+func TestRouterCircuitAsTexAndSVG(t *testing.T) {
+	svc := service.NewTeleportationService()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mux := http.NewServeMux()
+	NewRouter(svc, logger).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	session, _ := createSessionWithOptionsAndHost(t, server.URL, `{"preset":"1"}`)
+
+	tex := fetchCircuit(t, server.URL+"/api/sessions/"+session+"/circuit?format=tex", http.StatusOK)
+	if !strings.Contains(tex, `\begin{quantikz}`) || !strings.Contains(tex, `\gategroup`) {
+		t.Fatalf("expected a quantikz circuit with the current step grouped, got\n%s", tex)
+	}
+	svg := fetchCircuit(t, server.URL+"/api/sessions/"+session+"/circuit?format=svg", http.StatusOK)
+	if !strings.HasPrefix(svg, "<svg") || !strings.Contains(svg, `class="current-step"`) {
+		t.Fatalf("expected an SVG circuit with the current step highlighted, got\n%s", svg)
+	}
+}
+
+func TestRouterSuperdenseCoding(t *testing.T) {
+	svc := service.NewTeleportationService()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mux := http.NewServeMux()
+	NewRouter(svc, logger).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	createSessionWithOptions(t, server.URL, `{"protocol":"superdense","preset":"+"}`, http.StatusBadRequest)
+	created := createSessionWithOptions(t, server.URL, `{"protocol":"superdense"}`, http.StatusOK)
+	if created.Config.Protocol != teleportation.ProtocolSuperdense || created.Steps[1].Key != teleportation.StepEncode {
+		t.Fatalf("expected superdense steps, got %+v", created.Steps)
+	}
+	aliceToken := joinRole(t, server.URL, created.ID, "alice", "")
+	bobToken := joinRole(t, server.URL, created.ID, "bob", "")
+	advanceSession(t, server.URL, created.ID, aliceToken)
+
+	encodeBits(t, server.URL, created.ID, aliceToken, "2x", http.StatusBadRequest)
+	encodeBits(t, server.URL, created.ID, bobToken, "01", http.StatusForbidden)
+	encodeBits(t, server.URL, created.ID, aliceToken, "01", http.StatusOK)
+	advanceSession(t, server.URL, created.ID, aliceToken)
+	advanceSession(t, server.URL, created.ID, bobToken)
+	session := advanceSession(t, server.URL, created.ID, bobToken)
+	if session.Measurement == nil || session.Measurement.Bits() != "01" {
+		t.Fatalf("expected bob to decode 01, got %+v", session.Measurement)
+	}
+	fetchCircuit(t, server.URL+"/api/sessions/"+created.ID+"/circuit", http.StatusNotFound)
+}
+
+func TestRouterEntanglementSwappingWithCharlie(t *testing.T) {
+	svc := service.NewTeleportationService()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mux := http.NewServeMux()
+	NewRouter(svc, logger).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	plain := createSessionRequest(t, server.URL)
+	payload, _ := json.Marshal(map[string]string{"role": "charlie"})
+	resp, err := http.Post(server.URL+"/api/sessions/"+plain.ID+"/join", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("failed to join: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expected charlie to be unsupported in teleportation, got %d", resp.StatusCode)
+	}
+
+	createSessionWithOptions(t, server.URL, `{"protocol":"swapping","preset":"0"}`, http.StatusBadRequest)
+	created := createSessionWithOptions(t, server.URL, `{"protocol":"swapping"}`, http.StatusOK)
+	if _, ok := created.Participants[qubit.RoleCharlie]; !ok || len(created.Qubits) != 4 {
+		t.Fatalf("expected a charlie role and four qubits, got %+v", created.Participants)
+	}
+	aliceToken := joinRole(t, server.URL, created.ID, "alice", "")
+	charlieToken := joinRole(t, server.URL, created.ID, "charlie", "")
+	bobToken := joinRole(t, server.URL, created.ID, "bob", "")
+	advanceSession(t, server.URL, created.ID, aliceToken)
+	advanceSession(t, server.URL, created.ID, charlieToken)
+	advanceSession(t, server.URL, created.ID, charlieToken)
+	session := advanceSession(t, server.URL, created.ID, bobToken)
+	session = correctSession(t, server.URL, created.ID, bobToken, string(session.Measurement.Correction))
+	if session.Correction == nil || !session.Correction.Correct {
+		t.Fatalf("expected bob's correction to restore the pair, got %+v", session.Correction)
+	}
+}
+
+func TestRouterChainOfRelays(t *testing.T) {
+	svc := service.NewTeleportationService()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mux := http.NewServeMux()
+	NewRouter(svc, logger).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	createSessionWithOptions(t, server.URL, `{"protocol":"chain"}`, http.StatusBadRequest)
+	createSessionWithOptions(t, server.URL, `{"protocol":"chain","relays":9}`, http.StatusBadRequest)
+
+	created := createSessionWithOptions(t, server.URL, `{"protocol":"chain","relays":1,"preset":"+"}`, http.StatusOK)
+	if _, ok := created.Participants["relay1"]; !ok || len(created.Qubits) != 3 {
+		t.Fatalf("expected a relay1 role and three qubits, got %+v", created.Participants)
+	}
+	if view := getSessionRequest(t, server.URL, created.ID); view.ActiveHop == nil || view.ActiveHop.From != qubit.RoleAlice || view.ActiveHop.To != "relay1" {
+		t.Fatalf("expected the first hop to be active, got %+v", view.ActiveHop)
+	}
+	aliceToken := joinRole(t, server.URL, created.ID, "alice", "")
+	relayToken := joinRole(t, server.URL, created.ID, "relay1", "")
+	bobToken := joinRole(t, server.URL, created.ID, "bob", "")
+
+	session := advanceSession(t, server.URL, created.ID, aliceToken)
+	for _, hop := range []struct{ from, to string }{{aliceToken, relayToken}, {relayToken, bobToken}} {
+		advanceSession(t, server.URL, created.ID, hop.from)
+		advanceSession(t, server.URL, created.ID, hop.from)
+		session = advanceSession(t, server.URL, created.ID, hop.to)
+		session = correctSession(t, server.URL, created.ID, hop.to, string(session.Measurement.Correction))
+		session = advanceSession(t, server.URL, created.ID, hop.to)
+	}
+	if session.CurrentStep().Key != teleportation.StepComplete || len(session.Hops) != 2 || !session.Hops[1].Correction.Correct {
+		t.Fatalf("expected both hops archived at completion, got %+v", session.Hops)
+	}
+}
+
+func TestRouterBB84WithEavesdropper(t *testing.T) {
+	svc := service.NewTeleportationService()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mux := http.NewServeMux()
+	NewRouter(svc, logger).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	createSessionWithOptions(t, server.URL, `{"protocol":"bb84","qubits":65}`, http.StatusBadRequest)
+	created := createSessionWithOptions(t, server.URL, `{"protocol":"bb84","qubits":8,"eavesdropper":true,"seed":4}`, http.StatusOK)
+	if _, ok := created.Participants[qubit.RoleEve]; !ok || len(created.Qubits) != 8 || created.KeyExchange == nil {
+		t.Fatalf("expected an eve role, eight qubits and a key exchange, got %+v", created)
+	}
+	aliceToken := joinRole(t, server.URL, created.ID, "alice", "")
+	eveToken := joinRole(t, server.URL, created.ID, "eve", "")
+	bobToken := joinRole(t, server.URL, created.ID, "bob", "")
+	for _, token := range []string{aliceToken, eveToken, bobToken, aliceToken} {
+		advanceSession(t, server.URL, created.ID, token)
+	}
+	session := advanceSession(t, server.URL, created.ID, bobToken)
+	k := session.KeyExchange
+	if session.CurrentStep().Key != teleportation.StepComplete || k == nil || k.Sent != 8 || k.AliceBits != "" || k.BobBits == "" {
+		t.Fatalf("expected bob's view of the finished exchange, got %+v", k)
+	}
+	if k.KeyLength+len(k.Sample) != len(k.Sifted) && !k.Aborted {
+		t.Fatalf("expected the key to be the unsampled sifted bits, got %+v", k)
+	}
+}
+
+func TestRouterEveTampersWithClassicalBits(t *testing.T) {
+	svc := service.NewTeleportationService()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mux := http.NewServeMux()
+	NewRouter(svc, logger).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	created := createSessionWithOptions(t, server.URL, `{"preset":"0","eavesdropper":true}`, http.StatusOK)
+	aliceToken := joinRole(t, server.URL, created.ID, "alice", "")
+	eveToken := joinRole(t, server.URL, created.ID, "eve", "")
+	bobToken := joinRole(t, server.URL, created.ID, "bob", "")
+	for i := 0; i < 3; i++ {
+		advanceSession(t, server.URL, created.ID, aliceToken)
+	}
+
+	tamper := func(token, flip string, expectedStatus int) {
+		t.Helper()
+		payload, _ := json.Marshal(map[string]string{"token": token, "flip": flip})
+		resp, err := http.Post(server.URL+"/api/sessions/"+created.ID+"/tamper", "application/json", bytes.NewReader(payload))
+		if err != nil {
+			t.Fatalf("failed to tamper: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != expectedStatus {
+			t.Fatalf("expected tamper status %d, got %d", expectedStatus, resp.StatusCode)
+		}
+	}
+	tamper(eveToken, "xy", http.StatusBadRequest)
+	tamper(bobToken, "01", http.StatusForbidden)
+	tamper(eveToken, "01", http.StatusOK)
+
+	advanceSession(t, server.URL, created.ID, eveToken)
+	session := advanceSession(t, server.URL, created.ID, bobToken)
+	session = correctSession(t, server.URL, created.ID, bobToken, string(session.Measurement.Correction))
+	if session.Correction == nil || session.Correction.Correct || session.Correction.Fidelity > 1e-9 {
+		t.Fatalf("expected the flipped X bit to ruin |0>, got %+v", session.Correction)
+	}
+}
+
+func TestRouterControlledTeleportation(t *testing.T) {
+	svc := service.NewTeleportationService()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mux := http.NewServeMux()
+	NewRouter(svc, logger).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	createSessionWithOptions(t, server.URL, `{"protocol":"secret_sharing","qubits":65}`, http.StatusBadRequest)
+	created := createSessionWithOptions(t, server.URL, `{"protocol":"controlled","preset":"+","seed":9}`, http.StatusOK)
+	if _, ok := created.Participants[qubit.RoleCharlie]; !ok || len(created.Qubits) != 3 {
+		t.Fatalf("expected a charlie role and three qubits, got %+v", created)
+	}
+	aliceToken := joinRole(t, server.URL, created.ID, "alice", "")
+	bobToken := joinRole(t, server.URL, created.ID, "bob", "")
+	charlieToken := joinRole(t, server.URL, created.ID, "charlie", "")
+	for _, token := range []string{aliceToken, aliceToken, aliceToken, charlieToken} {
+		advanceSession(t, server.URL, created.ID, token)
+	}
+	session := advanceSession(t, server.URL, created.ID, bobToken)
+	if session.Control == nil || session.Measurement == nil || !session.Qubits[2].Hidden {
+		t.Fatalf("expected bob to learn both announcements, got %+v", session)
+	}
+	session = correctSession(t, server.URL, created.ID, bobToken, string(session.Control.Correction))
+	if session.Correction == nil || !session.Correction.Correct {
+		t.Fatalf("expected charlie's help to restore |+>, got %+v", session.Correction)
+	}
+}
+
+func encodeBits(t *testing.T, baseURL, sessionID, token, bits string, expectedStatus int) {
+	t.Helper()
+
+	payload, _ := json.Marshal(map[string]string{"token": token, "bits": bits})
+	resp, err := http.Post(baseURL+"/api/sessions/"+sessionID+"/encode", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("failed to encode bits: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != expectedStatus {
+		t.Fatalf("expected encode status %d, got %d", expectedStatus, resp.StatusCode)
+	}
+}
+
+func createSessionWithOptionsAndHost(t *testing.T, baseURL, options string) (string, string) {
+	t.Helper()
+
+	resp, err := http.Post(baseURL+"/api/sessions", "application/json", strings.NewReader(options))
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	defer resp.Body.Close()
+	var created struct {
+		ID        string `json:"id"`
+		HostToken string `json:"hostToken"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&created); err != nil {
+		t.Fatalf("failed to decode session: %v", err)
+	}
+	return created.ID, created.HostToken
+}
+
+func fetchCircuit(t *testing.T, url string, expectedStatus int) string {
+	t.Helper()
+
+	resp, err := http.Get(url)
+	if err != nil {
+		t.Fatalf("failed to fetch circuit: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != expectedStatus {
+		t.Fatalf("expected circuit status %d, got %d", expectedStatus, resp.StatusCode)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	return string(body)
+}
+
+func TestRouterRoutesReplayToItsHandler(t *testing.T) {
+	svc := service.NewTeleportationService()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	router := NewRouter(svc, logger)
+	mux := http.NewServeMux()
+	router.Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/api/sessions/abc/replay")
+	if err != nil {
+		t.Fatalf("failed to request replay: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected 404 without a replay handler, got %d", resp.StatusCode)
+	}
+
+	var routed string
+	router.HandleReplay(func(w http.ResponseWriter, _ *http.Request, id string) {
+		routed = id
+		w.WriteHeader(http.StatusTeapot)
+	})
+	resp, err = http.Get(server.URL + "/api/sessions/abc/replay?speed=2x")
+	if err != nil {
+		t.Fatalf("failed to request replay: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusTeapot || routed != "abc" {
+		t.Fatalf("expected the replay handler to serve session abc, got %d for %q", resp.StatusCode, routed)
+	}
+
+	resp, err = http.Post(server.URL+"/api/sessions/abc/replay", "application/json", nil)
+	if err != nil {
+		t.Fatalf("failed to post replay: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expected replay to be GET only, got %d", resp.StatusCode)
+	}
+}
+
+func TestRouterCapsRequestBodies(t *testing.T) {
+	svc := service.NewTeleportationService()
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	mux := http.NewServeMux()
+	NewRouter(svc, logger).Register(mux)
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	padded := `{"protocol":"teleportation","pad":"` + strings.Repeat("x", maxBodyBytes) + `"}`
+	resp, err := http.Post(server.URL+"/api/sessions", "application/json", strings.NewReader(padded))
+	if err != nil {
+		t.Fatalf("failed to create session: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected an oversized create to be refused with 413, got %d", resp.StatusCode)
+	}
+
+	session, _ := svc.CreateSession()
+	bundle, err := svc.ExportSession(session.ID, session.HostToken)
+	if err != nil {
+		t.Fatalf("expected export to succeed, got %v", err)
+	}
+	for len(bundle.Events) <= 10000 {
+		bundle.Events = append(bundle.Events, teleportation.Event{Type: teleportation.EventStepAdvanced})
+	}
+	payload, _ := json.Marshal(bundle)
+	resp, err = http.Post(server.URL+"/api/sessions/import", "application/json", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("failed to import: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || !strings.Contains(string(body), "too many events") {
+		t.Fatalf("expected a bundle with too many events to be refused, got %d %s", resp.StatusCode, body)
+	}
+}
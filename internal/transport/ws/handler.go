@@ -1,11 +1,16 @@
 package ws
 
 import (
+	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/gorilla/websocket"
 
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/domain/teleportation"
 	"quantum-teleport/internal/service"
 )
 
@@ -48,8 +53,8 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 
 	session, role, err := h.service.RegisterListener(sessionID, token, conn)
 	if err != nil {
-		h.logger.Warn("session missing", slog.String("session", sessionID))
-		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "session not found"))
+		h.logger.Warn("ws connect refused", slog.String("session", sessionID), slog.String("error", err.Error()))
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, err.Error()))
 		_ = conn.Close()
 		return
 	}
@@ -61,19 +66,85 @@ func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		Local:  service.LocalView{Role: role},
 	})
 	h.service.Broadcast(sessionID)
-	go h.readLoop(sessionID, conn)
+	go h.readLoop(sessionID, token, conn)
 }
 
-func (h *Handler) readLoop(sessionID string, conn *websocket.Conn) {
+// clientMessage is an inbound command sent by a participant over the socket.
+// ID is optional and echoed back as requestId in the ack or error reply.
+type clientMessage struct {
+	ID         string `json:"id"`
+	Type       string `json:"type"`
+	Correction string `json:"correction"`
+	Bits       string `json:"bits"`
+	Flip       string `json:"flip"`
+	Text       string `json:"text"`
+	Step       int    `json:"step"`
+	Role       string `json:"role"`
+}
+
+func (h *Handler) readLoop(sessionID string, token string, conn *websocket.Conn) {
 	defer func() {
-		h.service.UnregisterListener(sessionID, conn)
+		if err := h.service.UnregisterListener(sessionID, conn); err != nil {
+			h.logger.Error("disconnect not saved", slog.String("session", sessionID), slog.String("error", err.Error()))
+		}
 		_ = conn.Close()
 		h.logger.Info("ws disconnected", slog.String("session", sessionID))
 	}()
 
 	for {
-		if _, _, err := conn.ReadMessage(); err != nil {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
 			return
 		}
+		var msg clientMessage
+		if err := json.Unmarshal(data, &msg); err != nil {
+			h.reply(sessionID, conn, msg.ID, errors.New("invalid message"))
+			continue
+		}
+		h.reply(sessionID, conn, msg.ID, h.handleMessage(sessionID, token, msg))
+	}
+}
+
+// handleMessage executes a client command; state changes reach clients through the usual broadcast.
+func (h *Handler) handleMessage(sessionID string, token string, msg clientMessage) error {
+	var err error
+	switch msg.Type {
+	case "ping":
+	case "advance":
+		_, err = h.service.AdvanceStep(sessionID, token)
+	case "correct":
+		_, err = h.service.ApplyCorrection(sessionID, token, teleportation.Correction(strings.ToUpper(msg.Correction)))
+	case "encode":
+		_, err = h.service.EncodeBits(sessionID, token, msg.Bits)
+	case "tamper":
+		_, err = h.service.TamperBits(sessionID, token, msg.Flip)
+	case "annotate":
+		err = h.service.Annotate(sessionID, token, msg.Text)
+	case "leave":
+		// A successful leave closes this socket with reason "role released".
+		_, err = h.service.LeaveSession(sessionID, token)
+	case string(service.HostReset), string(service.HostRewind), string(service.HostKick),
+		string(service.HostRegenerate), string(service.HostLock), string(service.HostUnlock):
+		_, err = h.service.HostControl(sessionID, token, service.HostCommand{
+			Action: service.HostAction(msg.Type),
+			Step:   msg.Step,
+			Role:   qubit.Role(strings.ToLower(msg.Role)),
+		})
+	default:
+		err = errors.New("unknown command")
+	}
+	if err != nil {
+		h.logger.Warn("ws command failed", slog.String("session", sessionID), slog.String("command", msg.Type), slog.String("error", err.Error()))
+	}
+	return err
+}
+
+// reply answers a command with an ack or an error carrying its request ID.
+func (h *Handler) reply(sessionID string, conn *websocket.Conn, requestID string, err error) {
+	message := service.BroadcastMessage{Type: "ack", RequestID: requestID}
+	if err != nil {
+		message.Type = "error"
+		message.Error = err.Error()
 	}
+	h.service.WriteToListener(sessionID, conn, message)
 }
@@ -0,0 +1,41 @@
+package ws
+
+import (
+	"io"
+	"log/slog"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"quantum-teleport/internal/service"
+)
+
+func TestServeHTTPClosesWithTheRefusal(t *testing.T) {
+	svc := service.NewTeleportationService()
+	session, _ := svc.CreateSession()
+	server := httptest.NewServer(NewHandler(svc, slog.New(slog.NewTextHandler(io.Discard, nil))))
+	defer server.Close()
+
+	cases := []struct{ session, token, reason string }{
+		{"missing", "anything", "session not found"},
+		{session.ID, "stolen", "unknown participant token"},
+	}
+	for _, c := range cases {
+		query := url.Values{"session": {c.session}, "token": {c.token}}
+		conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/?"+query.Encode(), nil)
+		if err != nil {
+			t.Fatalf("failed to connect: %v", err)
+		}
+		_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+		_, _, err = conn.ReadMessage()
+		closeErr, ok := err.(*websocket.CloseError)
+		if !ok || closeErr.Text != c.reason {
+			t.Fatalf("expected the socket to close with %q, got %v", c.reason, err)
+		}
+		conn.Close()
+	}
+}
@@ -0,0 +1,128 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"quantum-teleport/internal/service"
+)
+
+// maxReplayGap caps the recorded pause between two frames at 1x, so a run
+// that sat idle for minutes still replays in class time.
+const maxReplayGap = 3 * time.Second
+
+// ServeReplay streams the recorded run of a session over WebSocket. The host
+// token is required. speed is a multiplier such as 1x or 2x applied to the
+// recorded timing, or "step" to send one frame per client "next" command.
+func (h *Handler) ServeReplay(w http.ResponseWriter, r *http.Request, sessionID string) {
+	speed, stepwise, err := parseSpeed(r.URL.Query().Get("speed"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	frames, err := h.service.ReplayFrames(sessionID, r.URL.Query().Get("token"))
+	if err != nil {
+		status := http.StatusConflict
+		switch err.Error() {
+		case "session not found":
+			status = http.StatusNotFound
+		case "unknown host token":
+			status = http.StatusForbidden
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		h.logger.Error("upgrade failed", slog.String("error", err.Error()))
+		return
+	}
+	defer conn.Close()
+	h.logger.Info("replay started", slog.String("session", sessionID), slog.Int("frames", len(frames)))
+
+	first := frames[0].Message
+	first.Type = "joined"
+	if err := conn.WriteJSON(first); err != nil {
+		return
+	}
+	if stepwise {
+		h.replayStepwise(conn, frames[1:])
+	} else {
+		h.replayTimed(conn, frames, speed)
+	}
+	_ = conn.WriteJSON(service.BroadcastMessage{Type: "replay_complete"})
+	_ = conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "replay finished"), time.Now().Add(time.Second))
+}
+
+// replayTimed sends the frames after the first one at the recorded pace divided by speed.
+func (h *Handler) replayTimed(conn *websocket.Conn, frames []service.ReplayFrame, speed float64) {
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for i := 1; i < len(frames); i++ {
+		gap := frames[i].At.Sub(frames[i-1].At)
+		if gap > maxReplayGap {
+			gap = maxReplayGap
+		}
+		select {
+		case <-closed:
+			return
+		case <-time.After(time.Duration(float64(gap) / speed)):
+		}
+		if err := conn.WriteJSON(frames[i].Message); err != nil {
+			return
+		}
+	}
+}
+
+// replayStepwise sends one frame per "next" command and answers others with an error.
+func (h *Handler) replayStepwise(conn *websocket.Conn, frames []service.ReplayFrame) {
+	for len(frames) > 0 {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var msg clientMessage
+		if err := json.Unmarshal(data, &msg); err != nil || msg.Type != "next" {
+			_ = conn.WriteJSON(service.BroadcastMessage{Type: "error", RequestID: msg.ID, Error: "unknown command"})
+			continue
+		}
+		message := frames[0].Message
+		message.RequestID = msg.ID
+		if err := conn.WriteJSON(message); err != nil {
+			return
+		}
+		frames = frames[1:]
+	}
+}
+
+// parseSpeed reads a playback speed: empty for 1x, "step", or a positive
+// multiplier with an optional trailing x.
+func parseSpeed(value string) (float64, bool, error) {
+	switch value = strings.ToLower(strings.TrimSpace(value)); value {
+	case "":
+		return 1, false, nil
+	case "step":
+		return 0, true, nil
+	}
+	speed, err := strconv.ParseFloat(strings.TrimSuffix(value, "x"), 64)
+	if err != nil || speed <= 0 || speed > 100 {
+		return 0, false, errors.New("invalid speed")
+	}
+	return speed, false, nil
+}
@@ -0,0 +1,162 @@
+package ws
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	"quantum-teleport/internal/domain/qubit"
+	"quantum-teleport/internal/service"
+)
+
+func TestParseSpeed(t *testing.T) {
+	cases := []struct {
+		value    string
+		speed    float64
+		stepwise bool
+		valid    bool
+	}{
+		{"", 1, false, true},
+		{"step", 0, true, true},
+		{" STEP ", 0, true, true},
+		{"2x", 2, false, true},
+		{"0.5", 0.5, false, true},
+		{"100X", 100, false, true},
+		{"0x", 0, false, false},
+		{"-1", 0, false, false},
+		{"101x", 0, false, false},
+		{"fast", 0, false, false},
+	}
+	for _, c := range cases {
+		speed, stepwise, err := parseSpeed(c.value)
+		if (err == nil) != c.valid {
+			t.Fatalf("parseSpeed(%q): expected valid=%v, got %v", c.value, c.valid, err)
+		}
+		if speed != c.speed || stepwise != c.stepwise {
+			t.Fatalf("parseSpeed(%q): expected %v/%v, got %v/%v", c.value, c.speed, c.stepwise, speed, stepwise)
+		}
+	}
+}
+
+func TestServeReplayRejectsBadRequests(t *testing.T) {
+	svc, sessionID, hostToken, aliceToken := recordedSession(t)
+	handler := NewHandler(svc, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	cases := []struct {
+		id, token, speed string
+		status           int
+	}{
+		{sessionID, hostToken, "fast", http.StatusBadRequest},
+		{"missing", hostToken, "1x", http.StatusNotFound},
+		{sessionID, aliceToken, "1x", http.StatusForbidden},
+	}
+	for _, c := range cases {
+		query := url.Values{"token": {c.token}, "speed": {c.speed}}
+		rec := httptest.NewRecorder()
+		handler.ServeReplay(rec, httptest.NewRequest(http.MethodGet, "/?"+query.Encode(), nil), c.id)
+		if rec.Code != c.status {
+			t.Fatalf("expected %d for %+v, got %d: %s", c.status, c, rec.Code, rec.Body.String())
+		}
+	}
+}
+
+func TestServeReplayStepwise(t *testing.T) {
+	svc, sessionID, hostToken, _ := recordedSession(t)
+	frames, err := svc.ReplayFrames(sessionID, hostToken)
+	if err != nil {
+		t.Fatalf("expected frames, got %v", err)
+	}
+	conn := dialReplay(t, svc, sessionID, hostToken, "step")
+
+	if joined := readReplay(t, conn); joined.Type != "joined" {
+		t.Fatalf("expected joined first, got %+v", joined)
+	}
+	_ = conn.WriteJSON(clientMessage{ID: "a1", Type: "advance"})
+	if rejected := readReplay(t, conn); rejected.Type != "error" || rejected.RequestID != "a1" {
+		t.Fatalf("expected live commands to be rejected, got %+v", rejected)
+	}
+	for i := 1; i < len(frames); i++ {
+		_ = conn.WriteJSON(clientMessage{ID: "n", Type: "next"})
+		frame := readReplay(t, conn)
+		if frame.Type != "state_update" || frame.RequestID != "n" || frame.Global.StepIndex != frames[i].Message.Global.StepIndex {
+			t.Fatalf("expected frame %d to answer next, got %+v", i, frame)
+		}
+	}
+	if done := readReplay(t, conn); done.Type != "replay_complete" {
+		t.Fatalf("expected replay_complete after the last frame, got %+v", done)
+	}
+}
+
+func TestServeReplayTimed(t *testing.T) {
+	svc, sessionID, hostToken, _ := recordedSession(t)
+	frames, err := svc.ReplayFrames(sessionID, hostToken)
+	if err != nil {
+		t.Fatalf("expected frames, got %v", err)
+	}
+	conn := dialReplay(t, svc, sessionID, hostToken, "100x")
+
+	if joined := readReplay(t, conn); joined.Type != "joined" {
+		t.Fatalf("expected joined first, got %+v", joined)
+	}
+	for i := 1; i < len(frames); i++ {
+		if frame := readReplay(t, conn); frame.Type != "state_update" || frame.RequestID != "" {
+			t.Fatalf("expected frame %d without a prompt, got %+v", i, frame)
+		}
+	}
+	if done := readReplay(t, conn); done.Type != "replay_complete" {
+		t.Fatalf("expected replay_complete after the last frame, got %+v", done)
+	}
+	if _, _, err := conn.ReadMessage(); !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+		t.Fatalf("expected a normal close, got %v", err)
+	}
+}
+
+func recordedSession(t *testing.T) (*service.TeleportationService, string, string, string) {
+	t.Helper()
+
+	svc := service.NewTeleportationService()
+	session, _ := svc.CreateSession()
+	alice, _ := svc.JoinSession(session.ID, qubit.RoleAlice, "")
+	for _, token := range []string{alice.Token, alice.Token, alice.Token} {
+		if _, err := svc.AdvanceStep(session.ID, token); err != nil {
+			t.Fatalf("expected advance to succeed, got %v", err)
+		}
+	}
+	return svc, session.ID, session.HostToken, alice.Token
+}
+
+func dialReplay(t *testing.T, svc *service.TeleportationService, sessionID, token, speed string) *websocket.Conn {
+	t.Helper()
+
+	handler := NewHandler(svc, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeReplay(w, r, sessionID)
+	}))
+	t.Cleanup(server.Close)
+
+	query := url.Values{"token": {token}, "speed": {speed}}
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http")+"/?"+query.Encode(), nil)
+	if err != nil {
+		t.Fatalf("failed to connect replay: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+func readReplay(t *testing.T, conn *websocket.Conn) service.BroadcastMessage {
+	t.Helper()
+
+	var msg service.BroadcastMessage
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("failed to read replay message: %v", err)
+	}
+	return msg
+}
@@ -0,0 +1,28 @@
+package utils
+
+import "math/rand"
+
+// SeededRand is a deterministic random stream that can be rebuilt from its seed
+// and the number of values already drawn, so it survives serialisation.
+type SeededRand struct {
+	Seed  int64 `json:"seed"`
+	Draws int   `json:"draws"`
+	rng   *rand.Rand
+}
+
+// NewSeededRand creates a stream positioned at its first value.
+func NewSeededRand(seed int64) *SeededRand {
+	return &SeededRand{Seed: seed}
+}
+
+// Float64 returns the next value in [0,1).
+func (r *SeededRand) Float64() float64 {
+	if r.rng == nil {
+		r.rng = rand.New(rand.NewSource(r.Seed))
+		for i := 0; i < r.Draws; i++ {
+			r.rng.Float64()
+		}
+	}
+	r.Draws++
+	return r.rng.Float64()
+}